@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// maxBatchIDs is the maximum number of movies that can be fetched by ID in a single request.
+const maxBatchIDs = 100
+
+// readIDs reads a comma-separated list of IDs from the query string, dropping the
+// duplicates, and adds an error to the validator if any of them is not a positive integer.
+func (app *application) readIDs(qs url.Values, key string, v *validator.Validator) []int64 {
+	ids := []int64{}
+	seen := map[int64]bool{}
+
+	for _, value := range app.readCSV(qs, key, []string{}) {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 1 {
+			v.AddError(key, "must contain only positive integers")
+			return nil
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// listMoviesByIDHandler is the handler that displays the movies with the IDs given in the
+// ids query parameter, in the same order, fetching them in a single query. The IDs of the
+// movies that don't exist are reported in the missing list. Only the fields and include
+// parameters of listMovieHandler apply.
+// Method: GET
+func (app *application) listMoviesByIDHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	ids := app.readIDs(qs, "ids", v)
+	if v.Valid() {
+		v.Check(len(ids) > 0, "ids", "must be provided")
+		v.Check(len(ids) <= maxBatchIDs, "ids", "must not contain more than "+strconv.Itoa(maxBatchIDs)+" IDs")
+	}
+	fields, include := app.readMovieFields(qs, v)
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movies, err := app.models.Movies.GetMany(ids, fields...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The movies are returned in the requested order, so the missing IDs are the ones
+	// that are not found while walking both lists.
+	missing := []int64{}
+	i := 0
+	for _, id := range ids {
+		if i < len(movies) && movies[i].ID == id {
+			i++
+			continue
+		}
+		missing = append(missing, id)
+	}
+
+	err = app.localizeMovies(r, movies)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, movies...)
+
+	representations, err := app.movieRepresentations(movies, fields, include)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The titles depend on the Accept-Language header.
+	headers := make(http.Header)
+	headers.Set("Vary", "Accept-Language")
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movies": representations, "missing": missing}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
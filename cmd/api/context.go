@@ -10,8 +10,12 @@ import (
 // Context key custom type.
 type contextKey string
 
-// Key used for setting and getting the user from the request context.
-const userContextKey = contextKey("user")
+// Keys used for setting and getting the user and the format of the movie runtimes from
+// the request context.
+const (
+	userContextKey          = contextKey("user")
+	runtimeFormatContextKey = contextKey("runtime_format")
+)
 
 // contextSetUser sets the user into the request context.
 func (app *application) contextSetUser(r *http.Request, user *data.User) *http.Request {
@@ -28,3 +32,21 @@ func (app *application) contextGetUser(r *http.Request) *data.User {
 
 	return user
 }
+
+// contextSetRuntimeFormat sets the format of the movie runtimes chosen by the client into
+// the request context.
+func (app *application) contextSetRuntimeFormat(r *http.Request, format string) *http.Request {
+	ctx := context.WithValue(r.Context(), runtimeFormatContextKey, format)
+	return r.WithContext(ctx)
+}
+
+// contextGetRuntimeFormat gets the format of the movie runtimes from the request context,
+// the legacy one if the client hasn't chosen any.
+func (app *application) contextGetRuntimeFormat(r *http.Request) string {
+	format, ok := r.Context().Value(runtimeFormatContextKey).(string)
+	if !ok {
+		return data.RuntimeFormatMins
+	}
+
+	return format
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/patch"
 )
 
 // logError is a generic helper for logging an error message along
@@ -62,12 +67,65 @@ func (app *application) methodNotAllowedResponse(w http.ResponseWriter, r *http.
 	app.errorResponse(w, r, http.StatusMethodNotAllowed, message)
 }
 
+// unsupportedMediaTypeResponse sends a 415 Unsupported Media Type status code and
+// JSON response to the client, listing the supported media types.
+func (app *application) unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, supported ...string) {
+	message := fmt.Sprintf("the request content type must be one of: %s", strings.Join(supported, ", "))
+	app.errorResponse(w, r, http.StatusUnsupportedMediaType, message)
+}
+
+// notAcceptableResponse sends a 406 Not Acceptable status code and JSON response to
+// the client, listing the media types that can be produced.
+func (app *application) notAcceptableResponse(w http.ResponseWriter, r *http.Request, supported ...string) {
+	message := fmt.Sprintf("the requested representation is not available, accepted media types are: %s", strings.Join(supported, ", "))
+	app.errorResponse(w, r, http.StatusNotAcceptable, message)
+}
+
 // editConflictResponse sends a data race condition error message to the client.
 func (app *application) editConflictResponse(w http.ResponseWriter, r *http.Request) {
 	message := "unable to update the record due to an edit conflict, please try again"
 	app.errorResponse(w, r, http.StatusConflict, message)
 }
 
+// duplicateMovieResponse sends a 409 Conflict status code and JSON response to the client,
+// along with the movies that the new movie likely duplicates.
+func (app *application) duplicateMovieResponse(w http.ResponseWriter, r *http.Request, candidates []*data.Movie) {
+	message := "the movie likely already exists, use force=true to create it anyway"
+
+	app.formatRuntimes(r, candidates...)
+
+	err := app.writeJSON(w, http.StatusConflict, envelope{"error": message, "candidates": candidates}, nil)
+	if err != nil {
+		app.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// preconditionFailedResponse sends a Precondition Failed error when the resource doesn't
+// match the If-Match header of the request.
+func (app *application) preconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the record has been modified since it was fetched, please fetch it again"
+	app.errorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
+// preconditionRequiredResponse sends a Precondition Required error when a write request
+// doesn't have the mandatory If-Match header.
+func (app *application) preconditionRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the If-Match header with the ETag of the record must be provided"
+	app.errorResponse(w, r, http.StatusPreconditionRequired, message)
+}
+
+// patchFailedResponse sends the error of a patch document that can't be applied to the
+// resource: Conflict if one of its tests failed, Unprocessable Entity otherwise.
+func (app *application) patchFailedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusUnprocessableEntity
+	if errors.Is(err, patch.ErrTestFailed) {
+		status = http.StatusConflict
+	}
+
+	app.errorResponse(w, r, status, fmt.Sprintf("unable to apply the patch: %s", err))
+}
+
 // rateLimitExceededResponse sends a Too Many Requests error.
 func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request) {
 	message := "rate limit exceeded"
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Media types supported by the export endpoint.
+var exportMediaTypes = map[string]string{
+	"csv":    "text/csv",
+	"ndjson": "application/x-ndjson",
+}
+
+// exportFlushInterval is the number of rows written between two flushes of the response.
+const exportFlushInterval = 100
+
+// exportMoviesHandler is the handler that streams all the movies matching the same filters
+// of listMovieHandler as CSV or NDJSON. The format is chosen by the format query parameter
+// or, if missing, by the Accept header.
+// Method: GET
+func (app *application) exportMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	criteria := app.readMovieCriteria(qs, v)
+	data.ValidateMovieCriteria(v, criteria)
+	format := app.readString(qs, "format", "")
+
+	if format != "" {
+		v.Check(validator.PermittedValue(format, "csv", "ndjson"), "format", "must be csv or ndjson")
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if format == "" {
+		format = negotiateExportFormat(r.Header.Get("Accept"))
+		if format == "" {
+			app.notAcceptableResponse(w, r, exportMediaTypes["csv"], exportMediaTypes["ndjson"])
+			return
+		}
+	}
+
+	err := app.normalizeCriteriaGenres(&criteria)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The export can last longer than the server write timeout, so remove the deadline
+	// for this response.
+	rc := http.NewResponseController(w)
+	err = rc.SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Write the rows while reading them from the database cursor.
+	var csvWriter *csv.Writer
+	var encoder *json.Encoder
+	started := false
+	rows := 0
+
+	// Headers are sent right before the first row, so that an error occurred while
+	// querying the database can still be reported with a proper status code.
+	start := func() error {
+		started = true
+
+		w.Header().Set("Content-Type", exportMediaTypes[format])
+		w.Header().Set("Content-Disposition", `attachment; filename="movies.`+format+`"`)
+		w.WriteHeader(http.StatusOK)
+
+		if format == "csv" {
+			csvWriter = csv.NewWriter(w)
+			return csvWriter.Write([]string{"id", "title", "year", "runtime", "genres", "version"})
+		}
+
+		encoder = json.NewEncoder(w)
+		return nil
+	}
+
+	writeMovie := func(movie *data.Movie) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		switch format {
+		case "csv":
+			err = csvWriter.Write([]string{
+				strconv.FormatInt(movie.ID, 10),
+				movie.Title,
+				strconv.FormatInt(int64(movie.Year), 10),
+				strconv.FormatInt(int64(movie.Runtime), 10),
+				strings.Join(movie.Genres, "|"),
+				strconv.FormatInt(int64(movie.Version), 10),
+			})
+		default:
+			app.formatRuntimes(r, movie)
+			err = encoder.Encode(movie)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Flush the buffered rows to the client from time to time.
+		rows++
+		if rows%exportFlushInterval == 0 {
+			return flushExport(rc, csvWriter)
+		}
+
+		return nil
+	}
+
+	// The rows stop being read as soon as the client goes away.
+	err = app.models.Movies.Stream(r.Context(), criteria, writeMovie)
+	if err != nil {
+		// Once the headers have been sent the status code can't be changed anymore,
+		// so the error can only be logged.
+		if started {
+			app.logError(r, err)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// No movie matched the filters: send an empty file (with the header only for CSV).
+	if !started {
+		err = start()
+		if err != nil {
+			app.logError(r, err)
+			return
+		}
+	}
+
+	err = flushExport(rc, csvWriter)
+	if err != nil {
+		app.logError(r, err)
+	}
+}
+
+// flushExport sends the rows buffered by the CSV writer (if any) and by the response
+// writer to the client.
+func flushExport(rc *http.ResponseController, csvWriter *csv.Writer) error {
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+	}
+
+	return rc.Flush()
+}
+
+// negotiateExportFormat returns the first export format accepted by the client in the
+// Accept header, NDJSON if the header is missing or accepts any type, or an empty string
+// if none of the supported media types is acceptable.
+func negotiateExportFormat(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return "ndjson"
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case exportMediaTypes["csv"]:
+			return "csv"
+		case exportMediaTypes["ndjson"], "*/*", "application/*":
+			return "ndjson"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// readExternalIDs reads the imdb, tmdb and wikidata parameters from the query string and
+// validates the IDs that have been provided.
+func (app *application) readExternalIDs(qs url.Values, v *validator.Validator) data.ExternalIDs {
+	ids := data.ExternalIDs{
+		IMDb:     app.readString(qs, "imdb", ""),
+		TMDB:     int64(app.readInt(qs, "tmdb", 0, v)),
+		Wikidata: app.readString(qs, "wikidata", ""),
+	}
+
+	data.ValidateExternalIDs(v, ids, "")
+
+	return ids
+}
+
+// lookupMovieHandler is the handler that displays the movie matching the external IDs given
+// in the imdb, tmdb and wikidata query parameters. Only the fields and include parameters of
+// showMovieHandler apply.
+// Method: GET
+func (app *application) lookupMovieHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	ids := app.readExternalIDs(qs, v)
+	if v.Valid() {
+		v.Check(ids != (data.ExternalIDs{}), "external_ids", "one of imdb, tmdb or wikidata must be provided")
+	}
+	fields, include := app.readMovieFields(qs, v)
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.GetByExternalIDs(ids, fields...)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.localizeMovies(r, []*data.Movie{movie})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, movie)
+
+	representation, err := app.movieRepresentations([]*data.Movie{movie}, fields, include)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The title depends on the Accept-Language header.
+	headers := make(http.Header)
+	headers.Set("Vary", "Accept-Language")
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": representation[0]}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// movieIncludes are the related resources that can be embedded in the movies with the
+// include query parameter.
+var movieIncludes = []string{"credits"}
+
+// readMovieFields reads the fields (sparse fieldset) and include query parameters, adding
+// an error to the validator for each unknown value.
+func (app *application) readMovieFields(qs url.Values, v *validator.Validator) ([]string, []string) {
+	fields := app.readCSV(qs, "fields", []string{})
+	data.ValidateMovieFields(v, fields)
+
+	include := app.readCSV(qs, "include", []string{})
+	for _, name := range include {
+		v.Check(validator.PermittedValue(name, movieIncludes...), "include", "must contain only "+strings.Join(movieIncludes, ", "))
+	}
+
+	return fields, include
+}
+
+// movieRepresentations returns the JSON representations of the movies, limited to the
+// given fields (the id is always kept) and embedding the included related resources.
+// The movies are returned as they are if there are no fields and nothing to include.
+func (app *application) movieRepresentations(movies []*data.Movie, fields []string, include []string) ([]any, error) {
+	representations := make([]any, len(movies))
+
+	if len(fields) == 0 && len(include) == 0 {
+		for i, movie := range movies {
+			representations[i] = movie
+		}
+		return representations, nil
+	}
+
+	// Fetch the related resources of all the movies at once.
+	ids := make([]int64, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+
+	var credits map[int64][]*data.Credit
+	if slices.Contains(include, "credits") {
+		var err error
+
+		credits, err = app.models.Credits.GetAllForMovies(ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, movie := range movies {
+		js, err := json.Marshal(movie)
+		if err != nil {
+			return nil, err
+		}
+
+		var members map[string]json.RawMessage
+
+		err = json.Unmarshal(js, &members)
+		if err != nil {
+			return nil, err
+		}
+
+		representation := make(map[string]any, len(members))
+		for name, value := range members {
+			// The original title and the locale of a localized title go with the title.
+			field := name
+			if name == "original_title" || name == "title_locale" {
+				field = "title"
+			}
+
+			if len(fields) == 0 || name == "id" || slices.Contains(fields, field) {
+				representation[name] = value
+			}
+		}
+
+		if credits != nil {
+			representation["credits"] = credits[movie.ID]
+			if credits[movie.ID] == nil {
+				representation["credits"] = []*data.Credit{}
+			}
+		}
+
+		representations[i] = representation
+	}
+
+	return representations, nil
+}
@@ -9,7 +9,9 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AlessioPani/go-greenlight/internal/data"
 	"github.com/AlessioPani/go-greenlight/internal/validator"
 	"github.com/julienschmidt/httprouter"
 )
@@ -48,6 +50,18 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data envelo
 	return nil
 }
 
+// formatRuntimes sets the format of the runtimes of the movies to the one chosen by the
+// client, which is applied when they are encoded to JSON.
+func (app *application) formatRuntimes(r *http.Request, movies ...*data.Movie) {
+	format := app.contextGetRuntimeFormat(r)
+
+	for _, movie := range movies {
+		if movie != nil {
+			movie.RuntimeFormat = format
+		}
+	}
+}
+
 // Define a readJSON() helper for reading requests. This takes the destination
 // http.ResponseWriter, the source *http.Request and the target variable dst and check for
 // several common errors regarding decoding a JSON (request body).
@@ -66,49 +80,19 @@ func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any
 	// Decode the request body to the destination.
 	err := dec.Decode(dst)
 	if err != nil {
-		var syntaxError *json.SyntaxError
-		var unmarshalTypeError *json.UnmarshalTypeError
-		var invalidUnmarshalError *json.InvalidUnmarshalError
 		var maxBytesError *http.MaxBytesError
 
 		switch {
-		// Check for SyntaxError errors.
-		case errors.As(err, &syntaxError):
-			return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
-
-		// Check for a generic ErrUnexpectedEOF error.
-		case errors.Is(err, io.ErrUnexpectedEOF):
-			return errors.New("body contains badly-formed JSON")
-
-		// Check for UnmarshalTypeError errors. These occur when the JSON value is the wrong type
-		// for the target destination. If the error relates to a specific field, then we include
-		// that in our error message to make it easier for the client to debug.
-		case errors.As(err, &unmarshalTypeError):
-			if unmarshalTypeError.Field != "" {
-				return fmt.Errorf("body contains incorrect JSON type for field %q", unmarshalTypeError.Field)
-			}
-			return fmt.Errorf("body contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
-
 		// Check for EOF error, that occurs when the body request is empty.
 		case errors.Is(err, io.EOF):
 			return errors.New("body must not be empty")
 
-		// Check if the JSON contains a field which cannot be mapped to the target destination.
-		case strings.HasPrefix(err.Error(), "json: unknown field "):
-			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			return fmt.Errorf("body contains unknown key %s", fieldName)
-
 		// Check for Max Byte Error that occurs when the request body exceeded our size limit of 1MB.
 		case errors.As(err, &maxBytesError):
 			return fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit)
 
-		// Check for InvalidUnmarshalError errors. These occur when we pass a nil pointer to the
-		// Decode function.
-		case errors.As(err, &invalidUnmarshalError):
-			panic(err)
-
 		default:
-			return err
+			return fmt.Errorf("body %w", jsonDecodeError(err))
 		}
 	}
 
@@ -123,19 +107,65 @@ func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any
 	return nil
 }
 
+// jsonDecodeError translates an error returned while decoding a JSON value into a
+// message that can be sent back to the client, e.g. "contains badly-formed JSON".
+func jsonDecodeError(err error) error {
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var invalidUnmarshalError *json.InvalidUnmarshalError
+
+	switch {
+	// Check for SyntaxError errors.
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("contains badly-formed JSON (at character %d)", syntaxError.Offset)
+
+	// Check for a generic ErrUnexpectedEOF error.
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("contains badly-formed JSON")
+
+	// Check for UnmarshalTypeError errors. These occur when the JSON value is the wrong type
+	// for the target destination. If the error relates to a specific field, then we include
+	// that in our error message to make it easier for the client to debug.
+	case errors.As(err, &unmarshalTypeError):
+		if unmarshalTypeError.Field != "" {
+			return fmt.Errorf("contains incorrect JSON type for field %q", unmarshalTypeError.Field)
+		}
+		return fmt.Errorf("contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
+
+	// Check if the JSON contains a field which cannot be mapped to the target destination.
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return fmt.Errorf("contains unknown key %s", fieldName)
+
+	// Check for InvalidUnmarshalError errors. These occur when we pass a nil pointer to the
+	// Decode function.
+	case errors.As(err, &invalidUnmarshalError):
+		panic(err)
+
+	default:
+		return err
+	}
+}
+
 // Retrieve the "id" URL parameter from the current request context, then convert it to
 // an integer and return it. If the operation isn't successful, return 0 and an error.
 func (app *application) readIDParam(r *http.Request) (int64, error) {
+	return app.readInt64Param(r, "id")
+}
+
+// readInt64Param retrieves a named URL parameter from the current request context and
+// converts it to a positive integer. If the operation isn't successful, return 0 and an error.
+func (app *application) readInt64Param(r *http.Request, name string) (int64, error) {
 	// Retrieve parameters from URL.
 	params := httprouter.ParamsFromContext(r.Context())
 
-	// Get ID.
-	id, err := strconv.ParseInt(params.ByName("id"), 10, 64)
-	if err != nil || id < 1 {
-		return 0, errors.New("invalid id parameter")
+	// Get the parameter.
+	i, err := strconv.ParseInt(params.ByName(name), 10, 64)
+	if err != nil || i < 1 {
+		return 0, fmt.Errorf("invalid %s parameter", name)
 	}
 
-	return id, nil
+	return i, nil
 }
 
 // readString returns a string value from the query string, or the default value if no match
@@ -189,6 +219,52 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 
 }
 
+// readTime reads a RFC 3339 timestamp or a YYYY-MM-DD date (at midnight UTC) from the
+// query string. If no matching key could be found or an error occurs when parsing, it
+// returns the zero time.
+func (app *application) readTime(qs url.Values, key string, v *validator.Validator) time.Time {
+	// Gets a string value from the query.
+	s := qs.Get(key)
+
+	// If no key exists, returns the zero time.
+	if s == "" {
+		return time.Time{}
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t
+	}
+
+	v.AddError(key, "must be a RFC 3339 timestamp or a YYYY-MM-DD date")
+	return time.Time{}
+}
+
+// readBool reads a string value from the query string and then converts it into a bool.
+// If no matching key could be found or an error occurs when converting, it returns
+// the provided default value.
+func (app *application) readBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	// Gets a string value from the query.
+	s := qs.Get(key)
+
+	// If no key exists, returns the default value.
+	if s == "" {
+		return defaultValue
+	}
+
+	// Checks for errors during the str -> bool conversation. If so, use the default value.
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+
+	return b
+}
+
 // background is an helper method that runs a function in background
 // in a separate goroutine and recovers from panic.
 func (app *application) background(fn func()) {
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/imaging"
+	"github.com/AlessioPani/go-greenlight/internal/storage"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Limits for a single uploaded image. A decoded image takes 4 bytes per pixel.
+const (
+	maxImageBytes  = 10_485_760
+	maxImagePixels = 12_000_000
+)
+
+// imageDecodes limits the number of uploaded images decoded at once, bounding the memory
+// used by the uploads to about 4*maxImagePixels bytes per slot.
+var imageDecodes = make(chan struct{}, 2)
+
+// imageCacheControl is the Cache-Control header of the image files. The storage key of an
+// image changes each time it is replaced, so a file never changes once stored.
+const imageCacheControl = "public, max-age=31536000, immutable"
+
+// readImageParams reads the movie ID and the kind of image from the URL, returning false
+// if the kind is unknown.
+func (app *application) readImageParams(r *http.Request) (int64, string, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		return 0, "", false
+	}
+
+	kind := httprouter.ParamsFromContext(r.Context()).ByName("kind")
+	if _, ok := data.ImageWidths[kind]; !ok {
+		return 0, "", false
+	}
+
+	return id, kind, true
+}
+
+// readImageFile reads the content of the "image" field of a multipart/form-data body.
+func (app *application) readImageFile(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	// Leave some room for the other fields and the boundaries of the form.
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageBytes+65_536)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("body must be a multipart form: %w", err)
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+
+			switch {
+			case errors.Is(err, io.EOF):
+				return nil, errors.New("body must contain an image field")
+			case errors.As(err, &maxBytesError):
+				return nil, fmt.Errorf("image must not be larger than %d bytes", maxImageBytes)
+			default:
+				return nil, fmt.Errorf("body contains a badly-formed multipart form: %w", err)
+			}
+		}
+
+		if part.FormName() != "image" {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(part, maxImageBytes+1))
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+
+			if errors.As(err, &maxBytesError) {
+				return nil, fmt.Errorf("image must not be larger than %d bytes", maxImageBytes)
+			}
+			return nil, fmt.Errorf("body contains a badly-formed multipart form: %w", err)
+		}
+
+		if len(content) > maxImageBytes {
+			return nil, fmt.Errorf("image must not be larger than %d bytes", maxImageBytes)
+		}
+
+		return content, nil
+	}
+}
+
+// movieImage returns the image of the given kind of the movie, nil if it has none.
+func movieImage(movie *data.Movie, kind string) *data.Image {
+	switch kind {
+	case "poster":
+		return movie.Poster
+	case "backdrop":
+		return movie.Backdrop
+	default:
+		return nil
+	}
+}
+
+// deleteImage removes the files of an image from the storage. Failures are only logged,
+// as the image is no longer referenced by the movie.
+func (app *application) deleteImage(image *data.Image) {
+	err := app.storage.Delete(image.Key)
+	if err != nil {
+		app.logger.Error(err.Error(), "key", image.Key)
+	}
+}
+
+// discardImage removes the files of an image that couldn't be referenced by the movie. The
+// key is derived from the content, so the files are kept if the movie references an image
+// with the same key, e.g. stored by a concurrent upload of the same content.
+func (app *application) discardImage(movieID int64, image *data.Image) {
+	movie, err := app.models.Movies.Get(movieID)
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		app.logger.Error(err.Error(), "key", image.Key)
+		return
+	}
+
+	if err == nil {
+		if current := movieImage(movie, image.Kind); current != nil && current.Key == image.Key {
+			return
+		}
+	}
+
+	app.deleteImage(image)
+}
+
+// uploadMovieImageHandler is the handler that replaces the poster or the backdrop of a
+// movie with the image of a multipart form, stored as a JPEG along with its thumbnails.
+// Method: PUT
+func (app *application) uploadMovieImageHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the movie ID and the kind of image from the URL.
+	id, kind, ok := app.readImageParams(r)
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		app.unsupportedMediaTypeResponse(w, r, "multipart/form-data")
+		return
+	}
+
+	content, err := app.readImageFile(w, r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Wait for a free decoding slot, unless the client goes away in the meantime.
+	select {
+	case imageDecodes <- struct{}{}:
+		defer func() { <-imageDecodes }()
+	case <-r.Context().Done():
+		return
+	}
+
+	// Decode the image, checking its actual format and dimensions. Re-encoding it drops
+	// its metadata (EXIF included).
+	img, err := imaging.Decode(content, maxImagePixels)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedFormat):
+			app.unsupportedMediaTypeResponse(w, r, imaging.ContentTypes...)
+		case errors.Is(err, imaging.ErrTooLarge):
+			app.failedValidationResponse(w, r, map[string]string{"image": fmt.Sprintf("must not have more than %d pixels", maxImagePixels)})
+		default:
+			app.failedValidationResponse(w, r, map[string]string{"image": "must be a valid image"})
+		}
+		return
+	}
+
+	// Fetch the existing movie record from the database.
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Verify that the movie in the database matches the preconditions of the request.
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	// The key is derived from the content, so that the files of an image never change.
+	image := &data.Image{
+		Kind: kind,
+		Key:  fmt.Sprintf("movies/%d/%s/%x", movie.ID, kind, sha256.Sum256(content)),
+	}
+	previous := movieImage(movie, kind)
+
+	// Store the original image and its thumbnails.
+	for _, width := range append([]int{0}, data.ImageWidths[kind]...) {
+		size := "original"
+		if width > 0 {
+			size = fmt.Sprintf("w%d", width)
+		}
+
+		encoded, err := imaging.EncodeJPEG(imaging.Resize(img, width))
+		if err == nil {
+			err = app.storage.Put(image.File(size), bytes.NewReader(encoded))
+		}
+		if err != nil {
+			app.discardImage(movie.ID, image)
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	// Reference the new image from the movie, then remove the files of the previous one.
+	user := app.contextGetUser(r)
+
+	err = app.models.Movies.SetImage(movie, kind, image, user.ID)
+	if err != nil {
+		app.discardImage(movie.ID, image)
+
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// The files of an image uploaded again are overwritten with identical ones, and must
+	// not be removed.
+	if previous != nil && previous.Key != image.Key {
+		app.deleteImage(previous)
+	}
+
+	// Write the updated movie record in a JSON response, along with its new ETag.
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.formatRuntimes(r, movie)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteMovieImageHandler is the handler that removes the poster or the backdrop of a movie.
+// Method: DELETE
+func (app *application) deleteMovieImageHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the movie ID and the kind of image from the URL.
+	id, kind, ok := app.readImageParams(r)
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Fetch the existing movie record from the database.
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	previous := movieImage(movie, kind)
+	if previous == nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Verify that the movie in the database matches the preconditions of the request.
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.Movies.SetImage(movie, kind, nil, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deleteImage(previous)
+
+	// Write the updated movie record in a JSON response, along with its new ETag.
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.formatRuntimes(r, movie)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showImageHandler is the handler that serves the files of the movie images, e.g.
+// /v1/images/movies/1/poster/3f2a.../w185.jpg. The files are public so that they can
+// be embedded in web pages, and cached forever as they never change.
+// Method: GET
+func (app *application) showImageHandler(w http.ResponseWriter, r *http.Request) {
+	key := httprouter.ParamsFromContext(r.Context()).ByName("key")
+	if !strings.HasSuffix(key, ".jpg") {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	file, err := app.storage.Get(key)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", imageCacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	_, err = io.Copy(w, file)
+	if err != nil {
+		app.logError(r, err)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Limits for a single import request.
+const (
+	maxImportBytes = 10_485_760
+	maxImportRows  = 10_000
+)
+
+// importRow is a movie parsed from a single row of an import file, along with the
+// line number of the row and the errors found while parsing or validating it.
+type importRow struct {
+	line   int
+	movie  *data.Movie
+	errors map[string]string
+}
+
+// importRowError reports the errors of a single row of an import file, along with the
+// movies it likely duplicates, if any.
+type importRowError struct {
+	Row        int               `json:"row"`
+	Errors     map[string]string `json:"errors"`
+	Candidates []*data.Movie     `json:"candidates,omitempty"`
+}
+
+// importReport is the result of an import, sent back to the client.
+type importReport struct {
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []importRowError `json:"errors"`
+}
+
+// importMoviesHandler is the handler that creates several movies from a NDJSON or CSV file.
+// Valid rows are inserted in a single transaction, invalid ones are reported back to the
+// client. With all_or_nothing=true no row is inserted if any of them is invalid. Since the
+// rows are inserted together, none is inserted if an external ID is already used.
+// As in createMovieHandler, the rows likely duplicating an existing movie are reported
+// along with the candidates, unless force=true.
+// Method: POST
+func (app *application) importMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	allOrNothing := app.readBool(r.URL.Query(), "all_or_nothing", false, v)
+	force := app.readBool(r.URL.Query(), "force", false, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Limit the size of the request body.
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	// Parse the rows depending on the content type of the request.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var rows []importRow
+	var err error
+
+	switch mediaType {
+	case "application/x-ndjson":
+		rows, err = app.readNDJSONMovies(r.Body)
+	case "text/csv":
+		rows, err = app.readCSVMovies(r.Body)
+	default:
+		app.unsupportedMediaTypeResponse(w, r, "application/x-ndjson", "text/csv")
+		return
+	}
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Load the genre catalog used to normalize the genres of every row.
+	catalog, err := app.models.Genres.Catalog()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Validate every row and build the report.
+	report := importReport{Rows: len(rows), Errors: []importRowError{}}
+	movies := []*data.Movie{}
+
+	for _, row := range rows {
+		if row.errors == nil {
+			row.movie.Genres = catalog.Normalize(row.movie.Genres)
+
+			v := validator.New()
+			if data.ValidateMovie(v, row.movie, catalog); !v.Valid() {
+				row.errors = v.Errors
+			}
+		}
+
+		if row.errors != nil {
+			report.Errors = append(report.Errors, importRowError{Row: row.line, Errors: row.errors})
+			continue
+		}
+
+		// Report the likely duplicates of the movie, if any.
+		if !force {
+			candidates, err := app.models.Movies.FindDuplicates(row.movie)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			if len(candidates) > 0 {
+				app.formatRuntimes(r, candidates...)
+
+				report.Errors = append(report.Errors, importRowError{
+					Row:        row.line,
+					Errors:     map[string]string{"row": "the movie likely already exists, use force=true to import it anyway"},
+					Candidates: candidates,
+				})
+				continue
+			}
+		}
+
+		movies = append(movies, row.movie)
+	}
+	report.Failed = len(report.Errors)
+
+	// In all-or-nothing mode a single invalid row aborts the whole import.
+	if allOrNothing && report.Failed > 0 {
+		app.errorResponse(w, r, http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	// Insert the valid movies into the db, recording the user who created them,
+	// and check for errors.
+	if len(movies) > 0 {
+		user := app.contextGetUser(r)
+
+		err = app.models.Movies.InsertMany(movies, user.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrDuplicateExternalID):
+				v.AddError("external_ids", "must not be used by another movie")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		app.suggestions.Clear()
+	}
+	report.Imported = len(movies)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// readNDJSONMovies parses a newline-delimited JSON body, where every non-blank line holds
+// a movie in the same format accepted by createMovieHandler.
+func (app *application) readNDJSONMovies(body io.Reader) ([]importRow, error) {
+	rows := []importRow{}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportBytes)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		// Skip blank lines.
+		js := bytes.TrimSpace(scanner.Bytes())
+		if len(js) == 0 {
+			continue
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("body must not contain more than %d rows", maxImportRows)
+		}
+
+		var input struct {
+			Title       string           `json:"title"`
+			Year        int32            `json:"year"`
+			Runtime     data.Runtime     `json:"runtime"`
+			Genres      []string         `json:"genres"`
+			ExternalIDs data.ExternalIDs `json:"external_ids"`
+		}
+
+		row := importRow{line: line}
+
+		dec := json.NewDecoder(bytes.NewReader(js))
+		dec.DisallowUnknownFields()
+
+		err := dec.Decode(&input)
+		if err == nil && dec.More() {
+			err = errors.New("must only contain a single JSON value")
+		}
+
+		if err != nil {
+			row.errors = map[string]string{"row": fmt.Sprintf("row %s", jsonDecodeError(err))}
+		} else {
+			row.movie = &data.Movie{
+				Title:       input.Title,
+				Year:        input.Year,
+				Runtime:     input.Runtime,
+				Genres:      input.Genres,
+				ExternalIDs: input.ExternalIDs,
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, importBodyError(err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("body must not be empty")
+	}
+
+	return rows, nil
+}
+
+// readCSVMovies parses a CSV body. The first record is a header which must contain the
+// title, year, runtime and genres columns, in any order. Runtime is expressed in any format
+// accepted by data.ParseRuntime (e.g. 135 or 2h 15m) and genres are separated by the "|"
+// character. The id and version columns written by exportMoviesHandler are ignored, so
+// that an exported file can be imported again.
+func (app *application) readCSVMovies(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	// Read the header and map each column to its position.
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("body must not be empty")
+		}
+		return nil, importBodyError(err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if validator.PermittedValue(name, "id", "version") {
+			continue
+		}
+		if !validator.PermittedValue(name, "title", "year", "runtime", "genres") {
+			return nil, fmt.Errorf("body contains unknown column %q", name)
+		}
+		columns[name] = i
+	}
+
+	for _, name := range []string{"title", "year", "runtime", "genres"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("body must contain the %q column", name)
+		}
+	}
+
+	rows := []importRow{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		// A record with the wrong number of fields is reported as an invalid row,
+		// any other error means that the file itself is malformed.
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) && errors.Is(parseError.Err, csv.ErrFieldCount) {
+			rows = append(rows, importRow{line: parseError.StartLine, errors: map[string]string{"row": "must contain one value for each column"}})
+			continue
+		}
+		if err != nil {
+			return nil, importBodyError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("body must not contain more than %d rows", maxImportRows)
+		}
+
+		rows = append(rows, parseCSVMovie(line, record, columns))
+	}
+
+	return rows, nil
+}
+
+// parseCSVMovie converts a CSV record into a movie, recording any conversion error.
+func parseCSVMovie(line int, record []string, columns map[string]int) importRow {
+	v := validator.New()
+
+	movie := &data.Movie{
+		Title: strings.TrimSpace(record[columns["title"]]),
+	}
+
+	year, err := strconv.ParseInt(strings.TrimSpace(record[columns["year"]]), 10, 32)
+	v.Check(err == nil, "year", "must be an integer value")
+	movie.Year = int32(year)
+
+	runtime, err := data.ParseRuntime(record[columns["runtime"]])
+	v.Check(err == nil, "runtime", "must be a number of minutes or a duration (e.g. 2h 15m)")
+	movie.Runtime = runtime
+
+	if genres := strings.TrimSpace(record[columns["genres"]]); genres != "" {
+		for _, genre := range strings.Split(genres, "|") {
+			movie.Genres = append(movie.Genres, strings.TrimSpace(genre))
+		}
+	}
+
+	if !v.Valid() {
+		return importRow{line: line, errors: v.Errors}
+	}
+
+	return importRow{line: line, movie: movie}
+}
+
+// importBodyError translates an error returned while reading an import body into a
+// message that can be sent back to the client.
+func importBodyError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	var parseError *csv.ParseError
+
+	switch {
+	case errors.As(err, &maxBytesError):
+		return fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit)
+	case errors.As(err, &parseError):
+		return fmt.Errorf("body contains malformed CSV (at line %d): %w", parseError.Line, parseError.Err)
+	case errors.Is(err, bufio.ErrTooLong):
+		return fmt.Errorf("body must not contain rows larger than %d bytes", maxImportBytes)
+	default:
+		return fmt.Errorf("body could not be read: %w", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// trashPurgeInterval is the interval between two runs of the purgeTrash job.
+const trashPurgeInterval = time.Hour
+
+// purgeTrash is a background job that permanently deletes, once every interval, the
+// movies that have been in the trash for longer than the configured retention period.
+// It runs until the context is cancelled.
+func (app *application) purgeTrash(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := app.models.Movies.Purge(time.Now().Add(-app.config.trash.retention))
+			if err != nil {
+				app.logger.Error(err.Error(), "job", "purge trash")
+				continue
+			}
+
+			if purged > 0 {
+				app.logger.Info("purged movies from the trash", "count", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test method used to test the purgeTrash background job.
+func TestPurgeTrash(t *testing.T) {
+	// Get test application config and capture its logs.
+	app := newTestApplication()
+	app.config.trash.retention = time.Hour
+
+	var logs bytes.Buffer
+	app.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	// Run the job with a short interval and stop it after a few runs.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		app.purgeTrash(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// Check that the job stops when the context is cancelled.
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the job did not stop after the context was cancelled")
+	}
+
+	// Check test results.
+	if !strings.Contains(logs.String(), "purged movies from the trash") {
+		t.Errorf("expected the job to purge movies, got logs %q", logs.String())
+	}
+}
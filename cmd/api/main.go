@@ -12,8 +12,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AlessioPani/go-greenlight/internal/cache"
 	"github.com/AlessioPani/go-greenlight/internal/data"
 	"github.com/AlessioPani/go-greenlight/internal/mailer"
+	"github.com/AlessioPani/go-greenlight/internal/storage"
 	_ "github.com/lib/pq"
 )
 
@@ -51,6 +53,29 @@ type config struct {
 		password string
 		sender   string
 	}
+	trash struct {
+		// retention is how long a deleted movie is kept in the trash before being purged.
+		// A zero value disables the purge.
+		retention time.Duration
+	}
+	preconditions struct {
+		// required makes the If-Match header mandatory on the requests writing a movie.
+		required bool
+	}
+	storage struct {
+		// dir is the directory where the uploaded images are stored.
+		dir string
+	}
+	suggest struct {
+		// cacheTTL is how long the title suggestions are cached. A zero value disables
+		// the cache.
+		cacheTTL time.Duration
+	}
+	similar struct {
+		// cacheTTL is the maximum time the similar movies of a movie are cached: they are
+		// computed again as soon as the movie changes. A zero value disables the cache.
+		cacheTTL time.Duration
+	}
 }
 
 // application is a struct that contains the dependencies for the application.
@@ -60,6 +85,12 @@ type application struct {
 	models data.Models
 	mailer mailer.Mailer
 	wg     sync.WaitGroup
+	// storage stores the uploaded images.
+	storage storage.Storage
+	// suggestions caches the title suggestions, keyed on the normalized prefix.
+	suggestions *cache.Cache[[]*data.MovieSuggestion]
+	// similar caches the ranking of the similar movies, keyed on the movie ID.
+	similar *cache.Cache[similarRanking]
 }
 
 func main() {
@@ -82,7 +113,13 @@ func main() {
 	flag.StringVar(&cfg.smtp.password, "smtp-password", "", "SMTP password")
 	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "Greenlight <no-reply@greenlight.net>", "SMTP sender")
 	flag.BoolVar(&cfg.enableMetrics, "metrics-enabled", true, "Enable metrics middleware")
+	flag.DurationVar(&cfg.trash.retention, "trash-retention", 30*24*time.Hour, "Retention period of deleted movies (0 to keep them forever)")
+	flag.BoolVar(&cfg.preconditions.required, "require-preconditions", false, "Require the If-Match header to update or delete movies")
+	flag.DurationVar(&cfg.suggest.cacheTTL, "suggest-cache-ttl", time.Minute, "Cache duration of the title suggestions (0 to disable the cache)")
+	flag.DurationVar(&cfg.similar.cacheTTL, "similar-cache-ttl", time.Hour, "Maximum cache duration of the similar movies (0 to disable the cache)")
+	flag.StringVar(&cfg.storage.dir, "storage-dir", "./uploads", "Directory of the uploaded images")
 	displayVersion := flag.Bool("version", false, "Display version and exit")
+	normalizeGenres := flag.Bool("normalize-genres", false, "Normalize the genres of the existing movies with the genre catalog and exit")
 	flag.Parse()
 
 	if *displayVersion {
@@ -102,6 +139,24 @@ func main() {
 	defer db.Close()
 	logger.Info("database connection pool established successfully")
 
+	// Normalize the genres of the existing movies and exit, if requested.
+	if *normalizeGenres {
+		n, err := data.NewModels(db).Genres.NormalizeMovies()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		logger.Info("genres normalized successfully", "movies", n)
+		return
+	}
+
+	// Open the storage of the uploaded images.
+	store, err := storage.NewLocal(cfg.storage.dir)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
 	// Custom metrics definition
 	// Version
 	expvar.NewString("version").Set(version)
@@ -123,10 +178,13 @@ func main() {
 
 	// Initialize application config with all the dependencies.
 	app := &application{
-		config: cfg,
-		logger: logger,
-		models: data.NewModels(db),
-		mailer: mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		config:      cfg,
+		logger:      logger,
+		models:      data.NewModels(db),
+		mailer:      mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		storage:     store,
+		suggestions: cache.New[[]*data.MovieSuggestion](cfg.suggest.cacheTTL, suggestionsCacheSize),
+		similar:     cache.New[similarRanking](cfg.similar.cacheTTL, similarCacheSize),
 	}
 
 	// Gets the configured mux with httprouter and runs it.
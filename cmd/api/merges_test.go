@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlessioPani/go-greenlight/internal/data/mocks"
+)
+
+// Test method used for the mergeMovieHandler http.Handler.
+func TestMergeMovieHandler(t *testing.T) {
+	// Get test application config and handler.
+	app := newTestApplication()
+	handler := http.Handler(app.routes())
+
+	// Merging requires the movies:admin permission, not only movies:write.
+	admin, writer := mocks.AdminToken, "12345678901234567890123456"
+
+	// Tests to be run.
+	tests := []struct {
+		name             string
+		token            string
+		url              string
+		payload          string
+		ifMatch          string
+		expectedResult   int
+		expectedLocation string
+	}{
+		{"movie merged", admin, "/v1/movies/2/merge", `{"into": 1}`, "", http.StatusOK, "/v1/movies/1"},
+		{"matching etag", admin, "/v1/movies/2/merge", `{"into": 1}`, `"1-0-0"`, http.StatusOK, "/v1/movies/1"},
+		{"stale etag", admin, "/v1/movies/2/merge", `{"into": 1}`, `"2-0-0"`, http.StatusPreconditionFailed, ""},
+		{"missing target", admin, "/v1/movies/2/merge", `{}`, "", http.StatusUnprocessableEntity, ""},
+		{"same movie", admin, "/v1/movies/1/merge", `{"into": 1}`, "", http.StatusUnprocessableEntity, ""},
+		{"target not found", admin, "/v1/movies/2/merge", `{"into": 4}`, "", http.StatusUnprocessableEntity, ""},
+		{"movie not found", admin, "/v1/movies/4/merge", `{"into": 1}`, "", http.StatusNotFound, ""},
+		{"invalid url", admin, "/v1/movies/foo/merge", `{"into": 1}`, "", http.StatusNotFound, ""},
+		{"invalid body", admin, "/v1/movies/2/merge", `{"into": "one"}`, "", http.StatusBadRequest, ""},
+		{"edit conflict", admin, "/v1/movies/3/merge", `{"into": 1}`, "", http.StatusConflict, ""},
+		{"admin permission missing", writer, "/v1/movies/2/merge", `{"into": 1}`, "", http.StatusForbidden, ""},
+	}
+
+	// Execute tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Create a test request with body, context and authorization.
+			req, err := http.NewRequest(http.MethodPost, test.url, bytes.NewBufferString(test.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.WithValue(context.Background(), userContextKey, mocks.ActiveUser)
+			req = req.WithContext(ctx)
+			req.Header.Set("Authorization", "Bearer "+test.token)
+			req.RemoteAddr = "localhost:8080"
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+
+			// Create a test response recorder (response writer).
+			rw := httptest.NewRecorder()
+
+			// Serve test request.
+			handler.ServeHTTP(rw, req)
+
+			// Check test results.
+			if rw.Code != test.expectedResult {
+				t.Fatalf("got %d, expected %d", rw.Code, test.expectedResult)
+			}
+
+			if location := rw.Header().Get("Location"); location != test.expectedLocation {
+				t.Errorf("got %q, expected %q", location, test.expectedLocation)
+			}
+		})
+	}
+}
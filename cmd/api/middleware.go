@@ -235,3 +235,25 @@ func (app *application) metrics(next http.Handler) http.Handler {
 		totalProcessingTimeMicroseconds.Add(duration)
 	})
 }
+
+// runtimeFormat is a middleware that reads the format of the movie runtimes chosen by the
+// client with the runtime_format query parameter: "mins" (the legacy "135 mins" string,
+// default), "minutes" (135) or "iso8601" ("PT135M"), and stores it in the request context.
+func (app *application) runtimeFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("runtime_format")
+		if format == "" || format == data.RuntimeFormatMins {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		v := validator.New()
+		if data.ValidateRuntimeFormat(v, format); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		r = app.contextSetRuntimeFormat(r, format)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,10 +3,15 @@ package main
 import (
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/patch"
 	"github.com/AlessioPani/go-greenlight/internal/validator"
 )
 
@@ -16,10 +21,11 @@ func (app *application) createMovieHandler(w http.ResponseWriter, r *http.Reques
 	// Anonymous struct to hold the information that we expect to be in the
 	// HTTP request body. This struct will be our *target decode destination*.
 	var input struct {
-		Title   string       `json:"title"`
-		Year    int32        `json:"year"`
-		Runtime data.Runtime `json:"runtime"`
-		Genres  []string     `json:"genres"`
+		Title       string           `json:"title"`
+		Year        int32            `json:"year"`
+		Runtime     data.Runtime     `json:"runtime"`
+		Genres      []string         `json:"genres"`
+		ExternalIDs data.ExternalIDs `json:"external_ids"`
 	}
 
 	// Initialize a new json.Decoder instance which reads from the request body, and
@@ -32,33 +38,72 @@ func (app *application) createMovieHandler(w http.ResponseWriter, r *http.Reques
 
 	// Fill a Movie struct with the input data.
 	movie := &data.Movie{
-		Title:   input.Title,
-		Year:    input.Year,
-		Runtime: input.Runtime,
-		Genres:  input.Genres,
+		Title:       input.Title,
+		Year:        input.Year,
+		Runtime:     input.Runtime,
+		Genres:      input.Genres,
+		ExternalIDs: input.ExternalIDs,
 	}
 
+	// Replace the genres with their slugs in the catalog.
+	catalog, err := app.models.Genres.Catalog()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	movie.Genres = catalog.Normalize(movie.Genres)
+
 	// Create a new instance of our custom Validator.
 	v := validator.New()
 
+	// The duplicate check is skipped if the client forces the creation.
+	force := app.readBool(r.URL.Query(), "force", false, v)
+
 	// Validate the Movie data. If errors occurred while processing the input JSON,
 	// send the appropriate error.
-	if data.ValidateMovie(v, movie); !v.Valid() {
+	if data.ValidateMovie(v, movie, catalog); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	// Insert the movie into the db and check for errors.
-	err = app.models.Movies.Insert(movie)
+	// Send the likely duplicates of the movie, if any, in a 409 Conflict response.
+	if !force {
+		candidates, err := app.models.Movies.FindDuplicates(movie)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if len(candidates) > 0 {
+			app.duplicateMovieResponse(w, r, candidates)
+			return
+		}
+	}
+
+	// Insert the movie into the db, recording the user who created it, and check for errors.
+	user := app.contextGetUser(r)
+
+	err = app.models.Movies.Insert(movie, user.ID)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrDuplicateExternalID):
+			v.AddError("external_ids", "must not be used by another movie")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
 
+	// The cached suggestions may miss the movie, or show it with a previous title.
+	app.suggestions.Clear()
+
 	// Set Location header to let the client know where to find
 	// the created resource and send the response.
 	headers := make(http.Header)
-	headers.Set("Location", fmt.Sprintf("v1/movies/%d", movie.ID))
+	headers.Set("Location", fmt.Sprintf("/v1/movies/%d", movie.ID))
+
+	app.formatRuntimes(r, movie)
 
 	err = app.writeJSON(w, http.StatusCreated, envelope{"movie": movie}, headers)
 	if err != nil {
@@ -77,12 +122,27 @@ func (app *application) showMovieHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Read the sparse fieldset and the related resources to embed.
+	v := validator.New()
+
+	fields, include := app.readMovieFields(r.URL.Query(), v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// The fields used by the ETag are always fetched.
+	columns := fields
+	if len(fields) > 0 {
+		columns = append(slices.Clone(fields), movieETagFields...)
+	}
+
 	// Gets the movie and checks for errors.
-	movie, err := app.models.Movies.Get(id)
+	movie, err := app.models.Movies.Get(id, columns...)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
-			app.notFoundResponse(w, r)
+			app.redirectMergedMovie(w, r, id)
 			return
 		default:
 			app.serverErrorResponse(w, r, err)
@@ -90,8 +150,43 @@ func (app *application) showMovieHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// The title depends on the Accept-Language header.
+	w.Header().Set("Vary", "Accept-Language")
+
+	err = app.localizeMovies(r, []*data.Movie{movie})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, movie)
+
+	representation, err := app.movieRepresentations([]*data.Movie{movie}, fields, include)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Accept-Patch", mergePatchType+", "+jsonPatchType)
+
+	// Send a 304 Not Modified response, without body, if the client already has the
+	// current representation of the movie. The related resources change without a new
+	// version of the movie, so there is no ETag when they are embedded.
+	if len(include) == 0 {
+		etag := movieETag(movie)
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag, true) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		headers.Set("ETag", etag)
+	}
+
 	// Writes back the movie into an enveloped JSON.
-	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": representation[0]}, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -121,63 +216,64 @@ func (app *application) updateMovieHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// If the request contains a X-Expected-Version header, verify that the movie
-	// version in the database matches the expected version specified in the header.
-	if r.Header.Get("X-Expected-Version") != "" {
-		if strconv.Itoa(int(movie.Version)) != r.Header.Get("X-Expected-Version") {
-			app.editConflictResponse(w, r)
-			return
-		}
+	// Verify that the movie in the database matches the preconditions of the request.
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
 	}
 
-	// Declare an input struct to hold the expected data from the client.
-	// Pointers are for a partial update of a record.
-	// If a field is not in the request body, its zero-value is nil and can
-	// be checked.
-	var input struct {
-		Title   *string       `json:"title"`
-		Year    *int32        `json:"year"`
-		Runtime *data.Runtime `json:"runtime"`
-		Genres  []string      `json:"genres"`
+	// Apply the changes in the request body to the movie record, depending on the format
+	// of the body: a partial movie, a JSON Merge Patch or a JSON Patch.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "", "application/json":
+		err = app.readMovieInput(w, r, movie)
+	case mergePatchType:
+		err = app.readMovieMergePatch(w, r, movie)
+	case jsonPatchType:
+		err = app.readMovieJSONPatch(w, r, movie)
+	default:
+		app.unsupportedMediaTypeResponse(w, r, "application/json", mergePatchType, jsonPatchType)
+		return
 	}
-
-	// Read the JSON request body data into the input struct.
-	err = app.readJSON(w, r, &input)
 	if err != nil {
-		app.badRequestResponse(w, r, err)
+		switch {
+		case errors.Is(err, patch.ErrTestFailed), errors.Is(err, patch.ErrPathNotFound):
+			app.patchFailedResponse(w, r, err)
+		default:
+			app.badRequestResponse(w, r, err)
+		}
 		return
 	}
 
-	// Copy the values from the request body to the appropriate fields of the movie
-	// record.
-	if input.Title != nil {
-		movie.Title = *input.Title
-	}
-	if input.Year != nil {
-		movie.Year = *input.Year
-	}
-	if input.Runtime != nil {
-		movie.Runtime = *input.Runtime
-	}
-	if input.Genres != nil {
-		movie.Genres = input.Genres
+	// Replace the genres with their slugs in the catalog.
+	catalog, err := app.models.Genres.Catalog()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
+	movie.Genres = catalog.Normalize(movie.Genres)
 
 	// Validate the updated movie record, sending the client a 422 Unprocessable Entity
 	// response if any checks fail.
 	v := validator.New()
-	if data.ValidateMovie(v, movie); !v.Valid() {
+	if data.ValidateMovie(v, movie, catalog); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	// Pass the updated movie record to the Update() method and checks
-	// for errors.
-	err = app.models.Movies.Update(movie)
+	// Pass the updated movie record to the Update() method, along with the user who
+	// updated it, and checks for errors.
+	user := app.contextGetUser(r)
+
+	err = app.models.Movies.Update(movie, user.ID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateExternalID):
+			v.AddError("external_ids", "must not be used by another movie")
+			app.failedValidationResponse(w, r, v.Errors)
 		default:
 			app.serverErrorResponse(w, r, err)
 
@@ -185,14 +281,104 @@ func (app *application) updateMovieHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Write the updated movie record in a JSON response.
-	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	app.suggestions.Clear()
+
+	// Write the updated movie record in a JSON response, along with its new ETag.
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.formatRuntimes(r, movie)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 }
 
+// expectedVersionMatches reports whether the version of the movie matches the one in the
+// X-Expected-Version header of the request. It always returns true if the header is missing.
+//
+// Deprecated: the If-Match header should be used instead, see checkMoviePreconditions.
+func (app *application) expectedVersionMatches(r *http.Request, movie *data.Movie) bool {
+	expectedVersion := r.Header.Get("X-Expected-Version")
+	if expectedVersion == "" {
+		return true
+	}
+
+	return strconv.Itoa(int(movie.Version)) == expectedVersion
+}
+
+// movieETagFields are the fields of a movie used by its ETag.
+var movieETagFields = []string{"version", "ratings_count", "average_rating"}
+
+// movieETag returns the strong ETag of the representation of a movie. Besides the version,
+// it contains the rating aggregates, as they change without a new version of the movie, and
+// the locale of the title, if it has been localized.
+func movieETag(movie *data.Movie) string {
+	etag := fmt.Sprintf("%d-%d-%s", movie.Version, movie.RatingsCount, strconv.FormatFloat(movie.AverageRating, 'f', -1, 64))
+	if movie.TitleLocale != "" {
+		etag += "-" + movie.TitleLocale
+	}
+
+	return strconv.Quote(etag)
+}
+
+// etagMatches reports whether the value of an If-Match or If-None-Match header, which is
+// "*" or a list of ETags, matches etag. Weak ETags (W/"...") match only with the weak
+// comparison, used by If-None-Match.
+func etagMatches(header string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" {
+			return true
+		}
+
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMoviePreconditions checks the If-Match header of a write request against the
+// current movie, as well as the legacy X-Expected-Version header. The If-Match header
+// is mandatory if the application is configured to require preconditions. If a check
+// fails, it sends the error response and returns false.
+func (app *application) checkMoviePreconditions(w http.ResponseWriter, r *http.Request, movie *data.Movie) bool {
+	ifMatch := r.Header.Get("If-Match")
+
+	// The client may hold the ETag of the movie localized for its Accept-Language header,
+	// so the localized copy of the movie is matched as well.
+	localized := *movie
+	if ifMatch != "" {
+		err := app.localizeMovies(r, []*data.Movie{&localized})
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return false
+		}
+	}
+
+	switch {
+	case ifMatch == "" && app.config.preconditions.required:
+		app.preconditionRequiredResponse(w, r)
+		return false
+	case ifMatch != "" && !etagMatches(ifMatch, movieETag(movie), false) && !etagMatches(ifMatch, movieETag(&localized), false):
+		app.preconditionFailedResponse(w, r)
+		return false
+	case !app.expectedVersionMatches(r, movie):
+		app.editConflictResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
 // deleteMovieHandler is the handler that deletes a movie.
 // Method: DELETE
 func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Request) {
@@ -203,19 +389,46 @@ func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Delete the movie from the database, sending a 404 Not Found response to the
-	// client if there isn't a matching record.
-	err = app.models.Movies.Delete(id)
+	// Fetch the movie to check the preconditions of the request, if any. The movie is
+	// then deleted only if it is still at the checked version.
+	var version int32
+	if r.Header.Get("If-Match") != "" || app.config.preconditions.required {
+		movie, err := app.models.Movies.Get(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.notFoundResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		if !app.checkMoviePreconditions(w, r, movie) {
+			return
+		}
+		version = movie.Version
+	}
+
+	// Move the movie to the trash, recording the user who deleted it, and send a 404
+	// Not Found response to the client if there isn't a matching record.
+	user := app.contextGetUser(r)
+
+	err = app.models.Movies.Delete(id, version, user.ID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	app.suggestions.Clear()
+
 	// Return a 200 OK status code along with a success message.
 	err = app.writeJSON(w, http.StatusOK, envelope{"message": "movie successfully deleted"}, nil)
 	if err != nil {
@@ -224,45 +437,220 @@ func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// listTrashHandler is the handler that displays the movies in the trash.
+// Method: GET
+func (app *application) listTrashHandler(w http.ResponseWriter, r *http.Request) {
+	var filters data.Filters
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	filters.Page = app.readInt(qs, "page", 1, v)
+	filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	filters.Sort = app.readString(qs, "sort", "-deleted_at")
+
+	// Supported sort keys.
+	filters.SortSafeList = []string{"id", "title", "deleted_at", "-id", "-title", "-deleted_at"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// If validation is ok, retrieve the movies in the trash.
+	movies, metadata, err := app.models.Movies.GetAllDeleted(filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, movies...)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "movies": movies}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// restoreMovieHandler is the handler that moves a movie out of the trash.
+// Method: POST
+func (app *application) restoreMovieHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the movie ID from the URL.
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Restore the movie, sending a 404 Not Found response to the client if there
+	// isn't a matching record in the trash.
+	err = app.models.Movies.Restore(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.suggestions.Clear()
+
+	// Fetch the restored movie to send it back to the client.
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, movie)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
 // listMovieHandler is the handler that displays all the movies. Compatible with queries.
+// With the ids query parameter, the given movies are fetched by ID instead.
 // Method: GET
 func (app *application) listMovieHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("ids") {
+		app.listMoviesByIDHandler(w, r)
+		return
+	}
+
 	// input struct to hold the expected values from the request query string.
 	var input struct {
-		Title  string
-		Genres []string
-		Filter data.Filters
+		Criteria data.MovieCriteria
+		Filter   data.Filters
+		Facets   []string
+		Fields   []string
+		Include  []string
 	}
 
 	v := validator.New()
 
 	qs := r.URL.Query()
 
-	input.Title = app.readString(qs, "title", "")
-	input.Genres = app.readCSV(qs, "genres", []string{})
+	input.Criteria = app.readMovieCriteria(qs, v)
+	data.ValidateMovieCriteria(v, input.Criteria)
+	input.Fields, input.Include = app.readMovieFields(qs, v)
+	input.Facets = app.readCSV(qs, "facets", []string{})
+	for _, facet := range input.Facets {
+		v.Check(validator.PermittedValue(facet, data.FacetNames...), "facets", "must contain only "+strings.Join(data.FacetNames, ", "))
+	}
 	input.Filter.Page = app.readInt(qs, "page", 1, v)
 	input.Filter.PageSize = app.readInt(qs, "page_size", 20, v)
 	input.Filter.Sort = app.readString(qs, "sort", "id")
+	input.Filter.Cursor = app.readString(qs, "cursor", "")
+	input.Filter.SkipTotal = !app.readBool(qs, "include_total", true, v)
 
-	// Supported sort keys.
-	input.Filter.SortSafeList = []string{"id", "title", "year", "runtime", "-id", "-title", "-year", "-runtime"}
+	// Supported sort keys. Relevance only sorts from the best match, so it has no
+	// descending counterpart.
+	input.Filter.SortSafeList = []string{"id", "title", "year", "runtime", "rating", "relevance", "-id", "-title", "-year", "-runtime", "-rating"}
+
+	// Relevance is computed against the searched title, from the best match.
+	if input.Filter.Sort == "relevance" {
+		v.Check(input.Criteria.Title != "", "sort", "relevance must be used together with title")
+	}
 
 	if data.ValidateFilters(v, input.Filter); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
+	err := app.normalizeCriteriaGenres(&input.Criteria)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	// If validation is ok, retrieve movies.
-	movies, metadata, err := app.models.Movies.GetAll(input.Title, input.Genres, input.Filter)
+	movies, metadata, err := app.models.Movies.GetAll(input.Criteria, input.Filter, input.Fields...)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	// Return a 200 OK status code along with a success message.
-	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "movies": movies}, nil)
+	err = app.localizeMovies(r, movies)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
+
+	app.formatRuntimes(r, movies...)
+
+	representations, err := app.movieRepresentations(movies, input.Fields, input.Include)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"metadata": metadata, "movies": representations}
+
+	// Count the matching movies by facet, if requested.
+	if len(input.Facets) > 0 {
+		facets, err := app.models.Movies.Facets(input.Criteria, input.Facets)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		env["facets"] = facets
+	}
+
+	// Return a 200 OK status code along with a success message. The titles depend on the
+	// Accept-Language header.
+	headers := make(http.Header)
+	headers.Set("Vary", "Accept-Language")
+
+	err = app.writeJSON(w, http.StatusOK, env, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// readMovieCriteria reads the query parameters used to select the movies to be listed or
+// exported, adding an error to the validator for each unparsable one.
+func (app *application) readMovieCriteria(qs url.Values, v *validator.Validator) data.MovieCriteria {
+	var criteria data.MovieCriteria
+
+	criteria.Title = app.readString(qs, "title", "")
+	criteria.TitleMode = app.readString(qs, "title_mode", "words")
+	criteria.Genres = app.readCSV(qs, "genres", []string{})
+	criteria.GenreMode = app.readString(qs, "genre_mode", "all")
+	criteria.YearMin = int32(app.readInt(qs, "year_min", 0, v))
+	criteria.YearMax = int32(app.readInt(qs, "year_max", 0, v))
+	criteria.RuntimeMin = int32(app.readInt(qs, "runtime_min", 0, v))
+	criteria.RuntimeMax = int32(app.readInt(qs, "runtime_max", 0, v))
+	criteria.CreatedAfter = app.readTime(qs, "created_after", v)
+	criteria.CreatedBefore = app.readTime(qs, "created_before", v)
+	criteria.PersonID = int64(app.readInt(qs, "person", 0, v))
+	criteria.Role = app.readString(qs, "role", "")
+	criteria.ExternalIDs = app.readExternalIDs(qs, v)
+	criteria.ReleasedIn = data.NormalizeCountry(app.readString(qs, "released_in", ""))
+	criteria.CertificationMax = data.NormalizeCertification(app.readString(qs, "certification_max", ""))
+
+	return criteria
+}
+
+// normalizeCriteriaGenres replaces the genres of the criteria with their slugs in the
+// catalog, so that aliases can be used to filter the movies too.
+func (app *application) normalizeCriteriaGenres(criteria *data.MovieCriteria) error {
+	if len(criteria.Genres) == 0 {
+		return nil
+	}
+
+	catalog, err := app.models.Genres.Catalog()
+	if err != nil {
+		return err
+	}
+	criteria.Genres = catalog.Normalize(criteria.Genres)
+
+	return nil
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/patch"
+)
+
+// Media types of the patch documents accepted by updateMovieHandler, besides the partial
+// movie sent as application/json.
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// movieDocument is the JSON document of the editable fields of a movie, to which the
+// patch documents are applied.
+type movieDocument struct {
+	Title       string           `json:"title"`
+	Year        int32            `json:"year"`
+	Runtime     data.Runtime     `json:"runtime"`
+	Genres      []string         `json:"genres"`
+	ExternalIDs data.ExternalIDs `json:"external_ids"`
+}
+
+// readMovieInput reads a partial movie from the request body and copies the provided
+// fields to the movie.
+func (app *application) readMovieInput(w http.ResponseWriter, r *http.Request, movie *data.Movie) error {
+	// Declare an input struct to hold the expected data from the client.
+	// Pointers are for a partial update of a record.
+	// If a field is not in the request body, its zero-value is nil and can
+	// be checked.
+	var input struct {
+		Title       *string       `json:"title"`
+		Year        *int32        `json:"year"`
+		Runtime     *data.Runtime `json:"runtime"`
+		Genres      []string      `json:"genres"`
+		ExternalIDs *struct {
+			IMDb     *string `json:"imdb"`
+			TMDB     *int64  `json:"tmdb"`
+			Wikidata *string `json:"wikidata"`
+		} `json:"external_ids"`
+	}
+
+	// Read the JSON request body data into the input struct.
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		return err
+	}
+
+	// Copy the values from the request body to the appropriate fields of the movie
+	// record.
+	if input.Title != nil {
+		movie.Title = *input.Title
+	}
+	if input.Year != nil {
+		movie.Year = *input.Year
+	}
+	if input.Runtime != nil {
+		movie.Runtime = *input.Runtime
+	}
+	if input.Genres != nil {
+		movie.Genres = input.Genres
+	}
+
+	// The external IDs are updated one by one, an empty (or zero) ID removing it.
+	if input.ExternalIDs != nil {
+		if input.ExternalIDs.IMDb != nil {
+			movie.ExternalIDs.IMDb = *input.ExternalIDs.IMDb
+		}
+		if input.ExternalIDs.TMDB != nil {
+			movie.ExternalIDs.TMDB = *input.ExternalIDs.TMDB
+		}
+		if input.ExternalIDs.Wikidata != nil {
+			movie.ExternalIDs.Wikidata = *input.ExternalIDs.Wikidata
+		}
+	}
+
+	return nil
+}
+
+// readMovieMergePatch reads a JSON Merge Patch (RFC 7396) from the request body and applies
+// it to the movie. A null value clears a field.
+func (app *application) readMovieMergePatch(w http.ResponseWriter, r *http.Request, movie *data.Movie) error {
+	var mergePatch any
+
+	err := app.readJSON(w, r, &mergePatch)
+	if err != nil {
+		return err
+	}
+
+	return patchMovie(movie, func(doc any) (any, error) {
+		return patch.Merge(doc, mergePatch), nil
+	})
+}
+
+// readMovieJSONPatch reads a JSON Patch (RFC 6902) from the request body and applies its
+// operations to the movie, e.g. {"op": "remove", "path": "/genres/1"}.
+func (app *application) readMovieJSONPatch(w http.ResponseWriter, r *http.Request, movie *data.Movie) error {
+	// The members of the operations that are not used must be ignored, so the patch
+	// is decoded without rejecting the unknown fields.
+	var js json.RawMessage
+
+	err := app.readJSON(w, r, &js)
+	if err != nil {
+		return err
+	}
+
+	var operations []patch.Operation
+
+	err = json.Unmarshal(js, &operations)
+	if err != nil {
+		return fmt.Errorf("body %w", jsonDecodeError(err))
+	}
+
+	return patchMovie(movie, func(doc any) (any, error) {
+		return patch.Apply(doc, operations)
+	})
+}
+
+// patchMovie applies a patch function to the JSON document of the movie, then copies the
+// patched document back to the movie. The movie is left untouched if an error occurs.
+func patchMovie(movie *data.Movie, fn func(doc any) (any, error)) error {
+	js, err := json.Marshal(movieDocument{
+		Title:       movie.Title,
+		Year:        movie.Year,
+		Runtime:     movie.Runtime,
+		Genres:      movie.Genres,
+		ExternalIDs: movie.ExternalIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	var doc any
+
+	err = json.Unmarshal(js, &doc)
+	if err != nil {
+		return err
+	}
+
+	doc, err = fn(doc)
+	if err != nil {
+		return err
+	}
+
+	js, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// The patched document must still be a movie.
+	var patched movieDocument
+
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(&patched)
+	if err != nil {
+		return fmt.Errorf("patched movie %w", jsonDecodeError(err))
+	}
+
+	movie.Title = patched.Title
+	movie.Year = patched.Year
+	movie.Runtime = patched.Runtime
+	movie.Genres = patched.Genres
+	movie.ExternalIDs = patched.ExternalIDs
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// relatedMovie is a movie related to another one, as listed by listRelatedMoviesHandler.
+type relatedMovie struct {
+	RelationID int64       `json:"relation_id"`
+	Type       string      `json:"type"` // Type of the relation as seen from the other movie
+	Movie      *data.Movie `json:"movie"`
+}
+
+// listRelatedMoviesHandler is the handler that displays the movies related to a movie,
+// with the type of their relation (e.g. "sequel_of" or "prequel_of"), and the collections
+// of the movie along with all their movies.
+// Method: GET
+func (app *application) listRelatedMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Check that the movie exists.
+	_, err = app.models.Movies.Get(movieID, "id")
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	relations, err := app.models.MovieRelations.GetAllForMovie(movieID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	ids := make([]int64, len(relations))
+	for i, relation := range relations {
+		ids[i], _ = relation.From(movieID)
+	}
+
+	movies, err := app.models.Movies.GetMany(ids)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	collections, err := app.models.Collections.GetAllForMovie(movieID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The movies of the relations and of the collections are localized together.
+	localized := movies
+	for _, collection := range collections {
+		for _, item := range collection.Movies {
+			localized = append(localized, item.Movie)
+		}
+	}
+
+	err = app.localizeMovies(r, localized)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, localized...)
+
+	moviesByID := make(map[int64]*data.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	related := []relatedMovie{}
+	for _, relation := range relations {
+		id, relationType := relation.From(movieID)
+
+		if movie, ok := moviesByID[id]; ok {
+			related = append(related, relatedMovie{RelationID: relation.ID, Type: relationType, Movie: movie})
+		}
+	}
+
+	// The titles depend on the Accept-Language header.
+	headers := make(http.Header)
+	headers.Set("Vary", "Accept-Language")
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"related": related, "collections": collections}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// createMovieRelationHandler is the handler that relates a movie to another one, e.g. as
+// its sequel.
+// Method: POST
+func (app *application) createMovieRelationHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		RelatedID int64  `json:"related_id"`
+		Type      string `json:"type"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	relation := &data.MovieRelation{
+		MovieID:   movieID,
+		RelatedID: input.RelatedID,
+		Type:      input.Type,
+	}
+
+	v := validator.New()
+
+	if data.ValidateMovieRelation(v, relation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Check that the related movie exists.
+	_, err = app.models.Movies.Get(relation.RelatedID, "id")
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("related_id", "must be an existing movie")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.MovieRelations.Insert(relation)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateRelation):
+			v.AddError("related_id", "is already related to this movie with the same type")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/movies/%d/related", movieID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"relation": relation}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// deleteMovieRelationHandler is the handler that removes a relation of a movie, in either
+// direction.
+// Method: DELETE
+func (app *application) deleteMovieRelationHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	id, err := app.readInt64Param(r, "relation_id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.MovieRelations.Delete(movieID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "relation successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -25,9 +25,84 @@ func (app *application) routes() http.Handler {
 	// Movies
 	router.HandlerFunc(http.MethodGet, "/v1/movies", app.requirePermission("movies:read", app.listMovieHandler))
 	router.HandlerFunc(http.MethodPost, "/v1/movies", app.requirePermission("movies:write", app.createMovieHandler))
-	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", app.requirePermission("movies:read", app.showMovieHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id", app.staticRoutes(app.methodNotAllowedResponse, map[string]http.HandlerFunc{
+		"import": app.requirePermission("movies:write", app.importMoviesHandler),
+	}))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", app.staticRoutes(app.requirePermission("movies:read", app.showMovieHandler), map[string]http.HandlerFunc{
+		"export":  app.requirePermission("movies:read", app.exportMoviesHandler),
+		"lookup":  app.requirePermission("movies:read", app.lookupMovieHandler),
+		"suggest": app.requirePermission("movies:read", app.suggestMoviesHandler),
+		"trash":   app.requirePermission("movies:write", app.listTrashHandler),
+	}))
 	router.HandlerFunc(http.MethodPatch, "/v1/movies/:id", app.requirePermission("movies:write", app.updateMovieHandler))
 	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id", app.requirePermission("movies:write", app.deleteMovieHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/restore", app.requirePermission("movies:write", app.restoreMovieHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/merge", app.requirePermission("movies:admin", app.mergeMovieHandler))
+
+	// Movie titles
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/titles", app.requirePermission("movies:read", app.listMovieTitlesHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/titles/:locale", app.requirePermission("movies:write", app.upsertMovieTitleHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/titles/:locale", app.requirePermission("movies:write", app.deleteMovieTitleHandler))
+
+	// Movie images
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/images/:kind", app.requirePermission("movies:write", app.uploadMovieImageHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/images/:kind", app.requirePermission("movies:write", app.deleteMovieImageHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/images/*key", app.showImageHandler)
+
+	// Movie versions
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/versions", app.requirePermission("movies:read", app.listMovieVersionsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/versions/:version", app.requirePermission("movies:read", app.showMovieVersionHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/versions/:version/revert", app.requirePermission("movies:write", app.revertMovieHandler))
+
+	// Credits
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/credits", app.requirePermission("movies:read", app.listMovieCreditsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/credits", app.requirePermission("movies:write", app.createMovieCreditHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/credits/:credit_id", app.requirePermission("movies:write", app.deleteMovieCreditHandler))
+
+	// Releases
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/releases", app.requirePermission("movies:read", app.listMovieReleasesHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/releases", app.requirePermission("movies:write", app.createMovieReleaseHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/releases/:release_id", app.requirePermission("movies:write", app.deleteMovieReleaseHandler))
+
+	// Movie relations
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/related", app.requirePermission("movies:read", app.listRelatedMoviesHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/similar", app.requirePermission("movies:read", app.listSimilarMoviesHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/relations", app.requirePermission("movies:write", app.createMovieRelationHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/relations/:relation_id", app.requirePermission("movies:write", app.deleteMovieRelationHandler))
+
+	// Collections
+	router.HandlerFunc(http.MethodGet, "/v1/collections", app.requirePermission("movies:read", app.listCollectionsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/collections", app.requirePermission("movies:write", app.createCollectionHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/collections/:id", app.requirePermission("movies:read", app.showCollectionHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/collections/:id", app.requirePermission("movies:write", app.updateCollectionHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/collections/:id", app.requirePermission("movies:write", app.deleteCollectionHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/collections/:id/movies/:movie_id", app.requirePermission("movies:write", app.setCollectionMovieHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/collections/:id/movies/:movie_id", app.requirePermission("movies:write", app.removeCollectionMovieHandler))
+
+	// Genres
+	router.HandlerFunc(http.MethodGet, "/v1/genres", app.requirePermission("movies:read", app.listGenresHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/genres", app.requirePermission("movies:write", app.createGenreHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/genres/:slug", app.requirePermission("movies:write", app.updateGenreHandler))
+
+	// People
+	router.HandlerFunc(http.MethodGet, "/v1/people", app.requirePermission("movies:read", app.listPeopleHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/people", app.requirePermission("movies:write", app.createPersonHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/people/:id", app.requirePermission("movies:read", app.showPersonHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/people/:id", app.requirePermission("movies:write", app.updatePersonHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/people/:id", app.requirePermission("movies:write", app.deletePersonHandler))
+
+	// Reviews
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/reviews", app.requirePermission("movies:read", app.listMovieReviewsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/reviews", app.requireActivatedUser(app.createReviewHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/reviews/:id", app.requirePermission("movies:read", app.showReviewHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/reviews/:id", app.requireActivatedUser(app.updateReviewHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/reviews/:id", app.requireActivatedUser(app.deleteReviewHandler))
+
+	// Watchlist
+	router.HandlerFunc(http.MethodGet, "/v1/watchlist", app.requirePermission("movies:read", app.listWatchlistHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/watchlist", app.requirePermission("movies:read", app.addWatchlistItemHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/watchlist/:id", app.requirePermission("movies:read", app.updateWatchlistItemHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/watchlist/:id", app.requirePermission("movies:read", app.removeWatchlistItemHandler))
 
 	// Users
 	router.HandlerFunc(http.MethodPost, "/v1/users", app.registerUserHandler)
@@ -45,10 +120,27 @@ func (app *application) routes() http.Handler {
 	// Middleware chain
 	chain := alice.Chain{}
 	if app.config.enableMetrics {
-		chain = alice.New(app.metrics, app.recoverPanic, app.rateLimit, app.authenticate)
+		chain = alice.New(app.metrics, app.recoverPanic, app.rateLimit, app.authenticate, app.runtimeFormat)
 	} else {
-		chain = alice.New(app.recoverPanic, app.rateLimit, app.authenticate)
+		chain = alice.New(app.recoverPanic, app.rateLimit, app.authenticate, app.runtimeFormat)
 	}
 
 	return chain.Then(router)
 }
+
+// staticRoutes wraps the handler of a route ending with the :id wildcard, serving instead
+// the handler of a static route (e.g. "/v1/movies/export") when the id parameter matches
+// its last segment. httprouter doesn't allow static segments and wildcards to share the
+// same position of a path.
+func (app *application) staticRoutes(next http.HandlerFunc, static map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := httprouter.ParamsFromContext(r.Context())
+
+		if handler, ok := static[params.ByName("id")]; ok {
+			handler(w, r)
+			return
+		}
+
+		next(w, r)
+	}
+}
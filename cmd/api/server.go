@@ -26,6 +26,16 @@ func (app *application) serve() error {
 
 	shutdownError := make(chan error)
 
+	// Starts the background jobs, which are stopped by cancelling the context on shutdown.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if app.config.trash.retention > 0 {
+		app.background(func() {
+			app.purgeTrash(jobsCtx, trashPurgeInterval)
+		})
+	}
+
 	// Starts a background goroutine to check for shutdowns.
 	go func() {
 		// Intercept syscalls to interrupt or terminate the process.
@@ -55,6 +65,7 @@ func (app *application) serve() error {
 		// shutting down the server.
 		app.logger.Info("completing background tasks", "addr", server.Addr)
 
+		stopJobs()
 		app.wg.Wait()
 		shutdownError <- nil
 	}()
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// similarCacheSize is the maximum number of movies whose similar movies are cached.
+const similarCacheSize = 10_000
+
+// similarRanking is the cached ranking of the movies similar to a movie, along with the
+// version of the movie it has been computed for.
+type similarRanking struct {
+	version int32
+	scores  []data.MovieScore
+}
+
+// similarMovie is a movie similar to another one, as listed by listSimilarMoviesHandler.
+type similarMovie struct {
+	Score float64     `json:"score"`
+	Movie *data.Movie `json:"movie"`
+}
+
+// listSimilarMoviesHandler is the handler that displays the movies similar to a movie,
+// ranked by genres, release year, runtime and ratings. The ranking is cached until the
+// movie changes, as it is computed on the whole catalog.
+// Method: GET
+func (app *application) listSimilarMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var filters data.Filters
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	filters.Page = app.readInt(qs, "page", 1, v)
+	filters.PageSize = app.readInt(qs, "page_size", 20, v)
+
+	// Movies are always ranked by their score.
+	filters.Sort = "-score"
+	filters.SortSafeList = []string{"-score"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.Get(id, "id", "year", "runtime", "genres", "version")
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	key := strconv.FormatInt(movie.ID, 10)
+
+	ranking, ok := app.similar.Get(key)
+	if !ok || ranking.version != movie.Version {
+		scores, err := app.models.Movies.Similar(movie)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		ranking = similarRanking{version: movie.Version, scores: scores}
+		app.similar.Set(key, ranking)
+	}
+
+	scores, metadata := data.PageScores(ranking.scores, filters)
+
+	ids := make([]int64, len(scores))
+	for i, score := range scores {
+		ids[i] = score.MovieID
+	}
+
+	movies, err := app.models.Movies.GetMany(ids)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.localizeMovies(r, movies)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.formatRuntimes(r, movies...)
+
+	moviesByID := make(map[int64]*data.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	// The movies moved to the trash since the ranking has been cached are skipped.
+	similar := []similarMovie{}
+	for _, score := range scores {
+		if movie, ok := moviesByID[score.MovieID]; ok {
+			similar = append(similar, similarMovie{Score: score.Score, Movie: movie})
+		}
+	}
+
+	headers := make(http.Header)
+	headers.Set("Vary", "Accept-Language")
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "similar": similar}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// suggestionsCacheSize is the maximum number of prefixes whose suggestions are cached.
+const suggestionsCacheSize = 10_000
+
+// suggestMoviesHandler is the handler that suggests the movies whose title starts with
+// the typed prefix. Suggestions are cached by normalized prefix, as they are requested
+// on every keystroke, until a movie is written.
+// Method: GET
+func (app *application) suggestMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	prefix := data.NormalizePrefix(app.readString(qs, "q", ""))
+	limit := app.readInt(qs, "limit", 10, v)
+
+	if data.ValidateSuggestion(v, prefix, limit); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	key := fmt.Sprintf("%d:%s", limit, prefix)
+
+	suggestions, ok := app.suggestions.Get(key)
+	if !ok {
+		var err error
+
+		suggestions, err = app.models.Movies.Suggest(prefix, limit)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		app.suggestions.Set(key, suggestions)
+	}
+
+	// Let the client reuse the suggestions for as long as they are cached.
+	headers := make(http.Header)
+	if ttl := app.config.suggest.cacheTTL; ttl > 0 {
+		headers.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
+	}
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"suggestions": suggestions}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
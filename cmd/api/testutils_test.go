@@ -3,7 +3,9 @@ package main
 import (
 	"log/slog"
 	"os"
+	"time"
 
+	"github.com/AlessioPani/go-greenlight/internal/cache"
 	"github.com/AlessioPani/go-greenlight/internal/data"
 	"github.com/AlessioPani/go-greenlight/internal/data/mocks"
 	"github.com/AlessioPani/go-greenlight/internal/mailer"
@@ -18,6 +20,8 @@ func newTestApplication() *application {
 	app.logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
 	app.mailer = mailer.New("localhost", 1025, "", "", "sender")
 	app.models = newTestModels()
+	app.suggestions = cache.New[[]*data.MovieSuggestion](time.Minute, suggestionsCacheSize)
+	app.similar = cache.New[similarRanking](time.Minute, similarCacheSize)
 
 	return &app
 }
@@ -26,9 +30,19 @@ func newTestApplication() *application {
 // models.
 func newTestModels() data.Models {
 	return data.Models{
-		Movies:      &mocks.MovieModel{},
-		Permissions: mocks.PermissionModel{},
-		Tokens:      &mocks.TokenModel{},
-		Users:       &mocks.UserModel{},
+		Collections:    &mocks.CollectionModel{},
+		Credits:        &mocks.CreditModel{},
+		Genres:         &mocks.GenreModel{},
+		Movies:         &mocks.MovieModel{},
+		MovieRelations: &mocks.MovieRelationModel{},
+		MovieTitles:    &mocks.MovieTitleModel{},
+		MovieVersions:  &mocks.MovieVersionModel{},
+		People:         &mocks.PersonModel{},
+		Permissions:    mocks.PermissionModel{},
+		Releases:       &mocks.ReleaseModel{},
+		Reviews:        &mocks.ReviewModel{},
+		Tokens:         &mocks.TokenModel{},
+		Users:          &mocks.UserModel{},
+		Watchlists:     &mocks.WatchlistModel{},
 	}
 }
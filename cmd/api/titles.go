@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+	"github.com/julienschmidt/httprouter"
+)
+
+// readAcceptLanguage returns the locales of the Accept-Language header of the request,
+// sorted from the most preferred one. The wildcard and the locales with a zero quality
+// are dropped, e.g. ["it-IT", "it", "en"] for "en;q=0.5, it-IT, it;q=0.8, de;q=0".
+func readAcceptLanguage(r *http.Request) []string {
+	type preference struct {
+		locale  string
+		quality float64
+	}
+
+	preferences := []preference{}
+
+	for _, value := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		locale, params, _ := strings.Cut(value, ";")
+		locale = strings.TrimSpace(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			preferences = append(preferences, preference{locale, quality})
+		}
+	}
+
+	// The stable sort keeps the order of the header between locales of equal quality.
+	slices.SortStableFunc(preferences, func(a, b preference) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	locales := make([]string, len(preferences))
+	for i, preference := range preferences {
+		locales[i] = preference.locale
+	}
+
+	return locales
+}
+
+// localizeMovies replaces the titles of the movies with the localized titles matching the
+// Accept-Language header of the request, keeping the original ones in OriginalTitle. The
+// movies without a matching title, or whose title has not been selected by a sparse
+// fieldset, are left untouched.
+func (app *application) localizeMovies(r *http.Request, movies []*data.Movie) error {
+	locales := readAcceptLanguage(r)
+	if len(locales) == 0 || len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+
+	titles, err := app.models.MovieTitles.GetAllForMovies(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, movie := range movies {
+		if movie.Title == "" {
+			continue
+		}
+
+		if title := data.LocalizedTitle(titles[movie.ID], locales); title != nil {
+			movie.OriginalTitle = movie.Title
+			movie.Title = title.Title
+			movie.TitleLocale = title.Locale
+		}
+	}
+
+	return nil
+}
+
+// listMovieTitlesHandler is the handler that displays the localized titles of a movie.
+// Method: GET
+func (app *application) listMovieTitlesHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the movie ID from the URL.
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Check that the movie exists.
+	_, err = app.models.Movies.Get(id, "id")
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	titles, err := app.models.MovieTitles.GetAllForMovie(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"titles": titles}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// upsertMovieTitleHandler is the handler that adds or replaces the title of a movie in the
+// locale of the URL.
+// Method: PUT
+func (app *application) upsertMovieTitleHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the movie ID from the URL.
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Title string `json:"title"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	title := &data.MovieTitle{
+		Locale: data.NormalizeLocale(httprouter.ParamsFromContext(r.Context()).ByName("locale")),
+		Title:  input.Title,
+	}
+
+	v := validator.New()
+	if data.ValidateMovieTitle(v, title); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Fetch the movie, whose version changes with its titles.
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Verify that the movie in the database matches the preconditions of the request.
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.MovieTitles.Upsert(movie, title, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Write the title in a JSON response, along with the new ETag of the movie.
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"title": title}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// deleteMovieTitleHandler is the handler that removes the title of a movie in the locale of
+// the URL.
+// Method: DELETE
+func (app *application) deleteMovieTitleHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the movie ID from the URL.
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	locale := data.NormalizeLocale(httprouter.ParamsFromContext(r.Context()).ByName("locale"))
+
+	// Fetch the movie, whose version changes with its titles.
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Verify that the movie in the database matches the preconditions of the request.
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.MovieTitles.Delete(movie, locale, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Send the new ETag of the movie along with the message.
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "title successfully deleted"}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// listMovieVersionsHandler is the handler that displays the history of a movie.
+// Method: GET
+func (app *application) listMovieVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Read the id from the request and validates it.
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var filters data.Filters
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	filters.Page = app.readInt(qs, "page", 1, v)
+	filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	filters.Sort = app.readString(qs, "sort", "-version")
+
+	// Supported sort keys.
+	filters.SortSafeList = []string{"version", "-version"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Check that the movie exists, so that an unknown movie is reported as such
+	// rather than as a movie without versions.
+	_, err = app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	versions, metadata, err := app.models.MovieVersions.GetAllForMovie(id, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	for _, version := range versions {
+		version.RuntimeFormat = app.contextGetRuntimeFormat(r)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "versions": versions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// showMovieVersionHandler is the handler that shows a version of a movie, along with the
+// changes made compared to the previous version.
+// Method: GET
+func (app *application) showMovieVersionHandler(w http.ResponseWriter, r *http.Request) {
+	movieVersion, ok := app.readMovieVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Get the previous version, if any, to compute the changes.
+	var previous *data.MovieVersion
+	if movieVersion.Version > 1 {
+		var err error
+
+		previous, err = app.models.MovieVersions.Get(movieVersion.MovieID, movieVersion.Version-1)
+		if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	movieVersion.RuntimeFormat = app.contextGetRuntimeFormat(r)
+
+	env := envelope{"version": movieVersion, "changes": movieVersion.Diff(previous)}
+
+	err := app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// revertMovieHandler is the handler that restores the data of a movie as it was in a
+// previous version. The revert is saved as a new version, through the same optimistic
+// locking used by updateMovieHandler.
+// Method: POST
+func (app *application) revertMovieHandler(w http.ResponseWriter, r *http.Request) {
+	movieVersion, ok := app.readMovieVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Fetch the current movie record from the database.
+	movie, err := app.models.Movies.Get(movieVersion.MovieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Verify that the movie in the database matches the preconditions of the request.
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	// Copy the data of the requested version to the movie record.
+	movie.Title = movieVersion.Title
+	movie.Year = movieVersion.Year
+	movie.Runtime = movieVersion.Runtime
+	movie.Genres = movieVersion.Genres
+
+	// Validate the reverted movie record, as validation rules and genres may have changed
+	// since the version was recorded.
+	catalog, err := app.models.Genres.Catalog()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	movie.Genres = catalog.Normalize(movie.Genres)
+
+	v := validator.New()
+	if data.ValidateMovie(v, movie, catalog); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.Movies.Update(movie, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.suggestions.Clear()
+
+	// Write the reverted movie record in a JSON response, along with its new ETag.
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.formatRuntimes(r, movie)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// readMovieVersion reads the id and version URL parameters and fetches the matching
+// movie version. If it fails, it sends the error response and returns false.
+func (app *application) readMovieVersion(w http.ResponseWriter, r *http.Request) (*data.MovieVersion, bool) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	version, err := app.readInt64Param(r, "version")
+	if err != nil || version > math.MaxInt32 {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	movieVersion, err := app.models.MovieVersions.Get(id, int32(version))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return movieVersion, true
+}
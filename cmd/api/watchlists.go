@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// listWatchlistHandler is the handler that displays the watchlist of the authenticated
+// user. Compatible with the same pagination queries of listMovieHandler, plus the watched
+// filter.
+// Method: GET
+func (app *application) listWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var filters data.Filters
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	// The watched filter is optional: if missing, all the movies are returned.
+	var watched *bool
+	if qs.Has("watched") {
+		b := app.readBool(qs, "watched", false, v)
+		watched = &b
+	}
+
+	filters.Page = app.readInt(qs, "page", 1, v)
+	filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	filters.Sort = app.readString(qs, "sort", "position")
+	filters.Cursor = app.readString(qs, "cursor", "")
+	filters.SkipTotal = !app.readBool(qs, "include_total", true, v)
+
+	// Supported sort keys.
+	filters.SortSafeList = []string{"position", "added_at", "title", "year", "-position", "-added_at", "-title", "-year"}
+
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	items, metadata, err := app.models.Watchlists.GetAllForUser(user.ID, watched, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	for _, item := range items {
+		app.formatRuntimes(r, item.Movie)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "watchlist": items}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// addWatchlistItemHandler is the handler that appends a movie to the watchlist of the
+// authenticated user, optionally marking it as already watched.
+// Method: POST
+func (app *application) addWatchlistItemHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		MovieID   int64      `json:"movie_id"`
+		WatchedOn *data.Date `json:"watched_on"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	v.Check(input.MovieID > 0, "movie_id", "must be a positive integer")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Fetch the movie, so that it can be returned along with the watchlist item.
+	movie, err := app.models.Movies.Get(input.MovieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("movie_id", "must be an existing movie")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	// The position is assigned when the movie is added, so it is valid as long as the
+	// other fields are.
+	item := &data.WatchlistItem{
+		UserID:    user.ID,
+		MovieID:   movie.ID,
+		Movie:     movie,
+		Position:  1,
+		WatchedOn: input.WatchedOn,
+	}
+
+	if data.ValidateWatchlistItem(v, item); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Watchlists.Add(item)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("movie_id", "must be an existing movie")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateWatchlistItem):
+			v.AddError("movie_id", "is already in your watchlist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/watchlist/%d", item.MovieID))
+
+	app.formatRuntimes(r, item.Movie)
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"watchlist_item": item}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// updateWatchlistItemHandler is the handler that moves a movie to another position of the
+// watchlist of the authenticated user, and marks it as watched or not watched.
+// Method: PATCH
+func (app *application) updateWatchlistItemHandler(w http.ResponseWriter, r *http.Request) {
+	item, ok := app.readWatchlistItem(w, r)
+	if !ok {
+		return
+	}
+
+	// Pointers are for a partial update of the item. If watched is true and no date is
+	// provided, the movie is marked as watched today.
+	var input struct {
+		Position  *int32     `json:"position"`
+		Watched   *bool      `json:"watched"`
+		WatchedOn *data.Date `json:"watched_on"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if input.Position != nil {
+		item.Position = *input.Position
+	}
+
+	switch {
+	case input.Watched != nil && !*input.Watched:
+		v.Check(input.WatchedOn == nil, "watched_on", "must not be provided if watched is false")
+		item.WatchedOn = nil
+	case input.WatchedOn != nil:
+		item.WatchedOn = input.WatchedOn
+	case input.Watched != nil && item.WatchedOn == nil:
+		today := data.Today()
+		item.WatchedOn = &today
+	}
+
+	if data.ValidateWatchlistItem(v, item); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Watchlists.Update(item)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.formatRuntimes(r, item.Movie)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"watchlist_item": item}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// removeWatchlistItemHandler is the handler that removes a movie from the watchlist of the
+// authenticated user.
+// Method: DELETE
+func (app *application) removeWatchlistItemHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.Watchlists.Remove(user.ID, movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "movie successfully removed from the watchlist"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// readWatchlistItem reads the id URL parameter and fetches the matching movie from the
+// watchlist of the authenticated user. If it fails, it sends the error response and
+// returns false.
+func (app *application) readWatchlistItem(w http.ResponseWriter, r *http.Request) (*data.WatchlistItem, bool) {
+	movieID, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	user := app.contextGetUser(r)
+
+	item, err := app.models.Watchlists.Get(user.ID, movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return item, true
+}
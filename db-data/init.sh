@@ -3,9 +3,10 @@
 set -e
 
 # Database setup
-echo "Adding extension and creating a normal operational user"
+echo "Adding extensions and creating a normal operational user"
 psql -v ON_ERROR_STOP=1 --username "postgres" --dbname "greenlight" <<-EOSQL
 	CREATE EXTENSION IF NOT EXISTS citext;
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
 	CREATE ROLE greenlight WITH LOGIN PASSWORD 'secret_password';
 	ALTER DATABASE greenlight OWNER TO greenlight;
 EOSQL
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// entry is a value of the cache along with its expiry time.
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// Cache is an in-memory cache of values keyed by string, safe for concurrent use. Values
+// expire after the ttl of the cache, and the cache never holds more than size entries:
+// when it is full, the expired entries are removed before adding a new one and, if none
+// has expired, an arbitrary entry is evicted.
+type Cache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]entry[V]
+	ttl     time.Duration
+	size    int
+}
+
+// New returns an empty cache, given the ttl of its values and its maximum number of entries.
+func New[V any](ttl time.Duration, size int) *Cache[V] {
+	return &Cache[V]{
+		entries: make(map[string]entry[V]),
+		ttl:     ttl,
+		size:    size,
+	}
+}
+
+// Get returns the value stored for a key and whether it has been found and not expired.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		var zero V
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+// Set stores a value for a key, replacing the existing one. A cache with a ttl or a size
+// not greater than zero stores nothing.
+func (c *Cache[V]) Set(key string, value V) {
+	if c.ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		c.evict()
+	}
+
+	c.entries[key] = entry[V]{value: value, expires: time.Now().Add(c.ttl)}
+}
+
+// Clear removes all the values of the cache.
+func (c *Cache[V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// evict removes the expired entries or, if none has expired, an arbitrary one. It must be
+// called with the mutex locked.
+func (c *Cache[V]) evict() {
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	if len(c.entries) < c.size {
+		return
+	}
+
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// Test method used to test storing and retrieving values from the cache.
+func TestCache(t *testing.T) {
+	c := New[int](time.Minute, 2)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	if value, ok := c.Get("a"); !ok || value != 1 {
+		t.Errorf("got %d (found: %t), expected 1", value, ok)
+	}
+
+	// The cache is full, so an entry must be evicted.
+	c.Set("c", 3)
+	if len(c.entries) != 2 {
+		t.Errorf("got %d entries, expected 2", len(c.entries))
+	}
+	if value, ok := c.Get("c"); !ok || value != 3 {
+		t.Errorf("got %d (found: %t), expected 3", value, ok)
+	}
+
+	c.Clear()
+	if len(c.entries) != 0 {
+		t.Errorf("got %d entries, expected 0", len(c.entries))
+	}
+}
+
+// Test method used to test the expiry of the values of the cache.
+func TestCacheExpiry(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		ttl           time.Duration
+		expectedFound bool
+	}{
+		{"not expired", time.Minute, true},
+		{"expired", time.Millisecond, false},
+		{"disabled", 0, false},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := New[string](test.ttl, 10)
+			c.Set("key", "value")
+
+			time.Sleep(5 * time.Millisecond)
+
+			if _, ok := c.Get("key"); ok != test.expectedFound {
+				t.Errorf("got found %t, expected %t", ok, test.expectedFound)
+			}
+		})
+	}
+}
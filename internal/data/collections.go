@@ -0,0 +1,509 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Collection is a struct that represents a collection of movies, e.g. a franchise.
+type Collection struct {
+	ID          int64              `json:"id"`
+	CreatedAt   time.Time          `json:"-"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Version     int32              `json:"version"`
+	Movies      []*CollectionMovie `json:"movies,omitempty"` // Only when the movies are fetched
+}
+
+// CollectionMovie is a struct that represents a movie in a collection, at the given position.
+type CollectionMovie struct {
+	CollectionID int64  `json:"-"`
+	MovieID      int64  `json:"movie_id"`
+	Movie        *Movie `json:"movie,omitempty"`
+	Position     int32  `json:"position"` // 0 to append the movie to the collection
+}
+
+// ValidateCollection validates a collection by checking its fields.
+func ValidateCollection(v *validator.Validator, collection *Collection) {
+	v.Check(collection.Name != "", "name", "must be provided")
+	v.Check(len(collection.Name) <= 500, "name", "must not be more than 500 bytes long")
+	v.Check(len(collection.Description) <= 5000, "description", "must not be more than 5000 bytes long")
+}
+
+// ValidateCollectionMovie validates a movie of a collection by checking its fields.
+func ValidateCollectionMovie(v *validator.Validator, item *CollectionMovie) {
+	v.Check(item.Position >= 0, "position", "must not be negative")
+}
+
+// Interface for the collection model.
+type CollectionModelInterface interface {
+	Insert(collection *Collection) error
+	Get(id int64) (*Collection, error)
+	GetAll(name string, filters Filters) ([]*Collection, Metadata, error)
+	GetAllForMovie(movieID int64) ([]*Collection, error)
+	Update(collection *Collection) error
+	Delete(id int64) error
+	SetMovie(item *CollectionMovie) error
+	RemoveMovie(collectionID int64, movieID int64) error
+}
+
+// Collection model struct that wraps a db connection pool.
+type CollectionModel struct {
+	DB *sql.DB
+}
+
+// Insert is a method for inserting a new record in the collections table.
+func (m *CollectionModel) Insert(collection *Collection) error {
+	query := `INSERT INTO collections (name, description)
+			  VALUES ($1, $2)
+			  RETURNING id, created_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, collection.Name, collection.Description).Scan(&collection.ID, &collection.CreatedAt, &collection.Version)
+}
+
+// Get is a method for fetching a specific record from the collections table, along with
+// its movies.
+func (m *CollectionModel) Get(id int64) (*Collection, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT id, created_at, name, description, version
+			  FROM collections
+			  WHERE id = $1`
+
+	var collection Collection
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&collection.ID,
+		&collection.CreatedAt,
+		&collection.Name,
+		&collection.Description,
+		&collection.Version,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	movies, err := m.getMovies(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Movies = movies[id]
+	if collection.Movies == nil {
+		collection.Movies = []*CollectionMovie{}
+	}
+
+	return &collection, nil
+}
+
+// GetAll is a method that retrieves the collections whose name contains the given words,
+// without their movies.
+func (m *CollectionModel) GetAll(name string, filters Filters) ([]*Collection, Metadata, error) {
+	query := fmt.Sprintf(`SELECT count(*) OVER(), id, created_at, name, description, version
+			  FROM collections
+			  WHERE (to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) OR $1 = '')
+			  ORDER BY %s %s, id ASC
+			  LIMIT $2 OFFSET $3`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, name, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	collections := []*Collection{}
+	for rows.Next() {
+		var collection Collection
+
+		err := rows.Scan(
+			&totalRecords,
+			&collection.ID,
+			&collection.CreatedAt,
+			&collection.Name,
+			&collection.Description,
+			&collection.Version)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		collections = append(collections, &collection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return collections, metadata, nil
+}
+
+// GetAllForMovie is a method that retrieves the collections containing a movie, sorted by
+// name, along with all their movies.
+func (m *CollectionModel) GetAllForMovie(movieID int64) ([]*Collection, error) {
+	query := `SELECT collections.id, collections.created_at, collections.name, collections.description, collections.version
+			  FROM collections
+			  INNER JOIN collection_movies ON collection_movies.collection_id = collections.id
+			  WHERE collection_movies.movie_id = $1
+			  ORDER BY collections.name ASC, collections.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	collections := []*Collection{}
+	for rows.Next() {
+		var collection Collection
+
+		err := rows.Scan(
+			&collection.ID,
+			&collection.CreatedAt,
+			&collection.Name,
+			&collection.Description,
+			&collection.Version)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, collection.ID)
+		collections = append(collections, &collection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	movies, err := m.getMovies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range collections {
+		collection.Movies = movies[collection.ID]
+	}
+
+	return collections, nil
+}
+
+// getMovies retrieves in a single query the movies of many collections, sorted by
+// position, mapped by collection ID. Collections without movies are not in the map.
+func (m *CollectionModel) getMovies(ctx context.Context, collectionIDs []int64) (map[int64][]*CollectionMovie, error) {
+	query := `SELECT collection_movies.collection_id, collection_movies.position,
+					 movies.id, movies.created_at, title, year, runtime, genres, movies.version, average_rating, ratings_count
+			  FROM collection_movies
+			  INNER JOIN movies ON movies.id = collection_movies.movie_id
+			  WHERE collection_movies.collection_id = ANY($1) AND movies.deleted_at IS NULL
+			  ORDER BY collection_movies.position ASC`
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(collectionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := map[int64][]*CollectionMovie{}
+	for rows.Next() {
+		item := CollectionMovie{Movie: &Movie{}}
+
+		err := rows.Scan(
+			&item.CollectionID,
+			&item.Position,
+			&item.Movie.ID,
+			&item.Movie.CreatedAt,
+			&item.Movie.Title,
+			&item.Movie.Year,
+			&item.Movie.Runtime,
+			pq.Array(&item.Movie.Genres),
+			&item.Movie.Version,
+			&item.Movie.AverageRating,
+			&item.Movie.RatingsCount)
+		if err != nil {
+			return nil, err
+		}
+		item.MovieID = item.Movie.ID
+		movies[item.CollectionID] = append(movies[item.CollectionID], &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// Update is a method for updating a specific record in the collections table. Like
+// MovieModel.Update, it returns an ErrEditConflict error if the collection has been
+// changed since it was read.
+func (m *CollectionModel) Update(collection *Collection) error {
+	query := `UPDATE collections
+			  SET name = $1, description = $2, version = version + 1
+			  WHERE id = $3 AND version = $4
+			  RETURNING version`
+
+	args := []any{collection.Name, collection.Description, collection.ID, collection.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&collection.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete is a method for deleting a specific record from the collections table. The
+// movies of the collection are left untouched.
+func (m *CollectionModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM collections
+			  WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// lockCollection locks a collection until the end of the transaction, so that concurrent
+// changes don't mix up the positions of its movies. The collection is locked rather than
+// its movies, which would lock nothing while the collection is empty.
+func lockCollection(ctx context.Context, tx *sql.Tx, collectionID int64) error {
+	_, err := tx.ExecContext(ctx, "SELECT id FROM collections WHERE id = $1 FOR NO KEY UPDATE", collectionID)
+	return err
+}
+
+// SetMovie is a method used to add a movie to a collection, or to move it if it is already
+// in the collection. The movies between the old and the new position are shifted. A
+// position past the end of the collection moves the movie to the end, and a position of 0
+// appends a new movie or leaves a movie in place. The position is set to the actual one.
+func (m *CollectionModel) SetMovie(item *CollectionMovie) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockCollection(ctx, tx, item.CollectionID)
+	if err != nil {
+		return err
+	}
+
+	// Current position of the movie, 0 if it is not in the collection yet, and number of
+	// movies of the collection.
+	query := `SELECT coalesce((SELECT position FROM collection_movies WHERE collection_id = $1 AND movie_id = $2), 0),
+					 (SELECT count(*) FROM collection_movies WHERE collection_id = $1)`
+
+	var current, length int32
+
+	err = tx.QueryRowContext(ctx, query, item.CollectionID, item.MovieID).Scan(&current, &length)
+	if err != nil {
+		return err
+	}
+
+	if current == 0 {
+		// A new movie takes a new position at the end of the collection.
+		length++
+
+		if item.Position == 0 || item.Position > length {
+			item.Position = length
+		}
+
+		query = `UPDATE collection_movies SET position = position + 1 WHERE collection_id = $1 AND position >= $2`
+		_, err = tx.ExecContext(ctx, query, item.CollectionID, item.Position)
+		if err != nil {
+			return err
+		}
+
+		// Only movies that are not in the trash can be added.
+		query = `INSERT INTO collection_movies (collection_id, movie_id, position)
+				 SELECT $1, id, $3
+				 FROM movies
+				 WHERE id = $2 AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, query, item.CollectionID, item.MovieID, item.Position)
+		if err != nil {
+			switch {
+			case strings.Contains(err.Error(), "collection_movies_collection_id_fkey"):
+				return ErrRecordNotFound
+			default:
+				return err
+			}
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		return tx.Commit()
+	}
+
+	switch {
+	case item.Position == 0:
+		item.Position = current
+	case item.Position > length:
+		item.Position = length
+	}
+
+	// Close the gap left by the movie, then make room at its new position.
+	if item.Position != current {
+		query = `UPDATE collection_movies SET position = position - 1 WHERE collection_id = $1 AND position > $2`
+		_, err = tx.ExecContext(ctx, query, item.CollectionID, current)
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE collection_movies SET position = position + 1 WHERE collection_id = $1 AND position >= $2 AND movie_id <> $3`
+		_, err = tx.ExecContext(ctx, query, item.CollectionID, item.Position, item.MovieID)
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE collection_movies SET position = $1 WHERE collection_id = $2 AND movie_id = $3`
+		_, err = tx.ExecContext(ctx, query, item.Position, item.CollectionID, item.MovieID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RemoveMovie is a method used to remove a movie from a collection, shifting the
+// following movies back.
+func (m *CollectionModel) RemoveMovie(collectionID int64, movieID int64) error {
+	if movieID < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM collection_movies
+			  WHERE collection_id = $1 AND movie_id = $2
+			  RETURNING position`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockCollection(ctx, tx, collectionID)
+	if err != nil {
+		return err
+	}
+
+	var position int32
+
+	err = tx.QueryRowContext(ctx, query, collectionID, movieID).Scan(&position)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	query = `UPDATE collection_movies SET position = position - 1 WHERE collection_id = $1 AND position > $2`
+	_, err = tx.ExecContext(ctx, query, collectionID, position)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// removeFromCollections removes movies from all their collections, shifting the following
+// movies back, within the transaction that purges or merges them.
+func removeFromCollections(ctx context.Context, tx *sql.Tx, movieIDs []int64) error {
+	// Lock the collections of the movies, in a consistent order, as lockCollection does.
+	query := `SELECT id FROM collections
+			  WHERE id IN (SELECT collection_id FROM collection_movies WHERE movie_id = ANY($1))
+			  ORDER BY id
+			  FOR NO KEY UPDATE`
+
+	_, err := tx.ExecContext(ctx, query, pq.Array(movieIDs))
+	if err != nil {
+		return err
+	}
+
+	// Each movie goes back by the number of removed movies placed before it.
+	query = `UPDATE collection_movies
+			  SET position = collection_movies.position - (
+			  	  SELECT count(*) FROM collection_movies AS removed
+			  	  WHERE removed.collection_id = collection_movies.collection_id
+			  	  AND removed.movie_id = ANY($1) AND removed.position < collection_movies.position)
+			  WHERE NOT collection_movies.movie_id = ANY($1)
+			  AND collection_movies.collection_id IN (SELECT collection_id FROM collection_movies WHERE movie_id = ANY($1))`
+
+	_, err = tx.ExecContext(ctx, query, pq.Array(movieIDs))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM collection_movies WHERE movie_id = ANY($1)`, pq.Array(movieIDs))
+	return err
+}
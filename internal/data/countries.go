@@ -0,0 +1,84 @@
+package data
+
+import "strings"
+
+// countryCodes are the ISO 3166-1 alpha-2 codes of the countries.
+var countryCodes = makeSet(strings.Fields(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+	BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+	CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+	DE DJ DK DM DO DZ
+	EC EE EG EH ER ES ET
+	FI FJ FK FM FO FR
+	GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+	HK HM HN HR HT HU
+	ID IE IL IM IN IO IQ IR IS IT
+	JE JM JO JP
+	KE KG KH KI KM KN KP KR KW KY KZ
+	LA LB LC LI LK LR LS LT LU LV LY
+	MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+	NA NC NE NF NG NI NL NO NP NR NU NZ
+	OM
+	PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+	QA
+	RE RO RS RU RW
+	SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+	TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+	UA UG UM US UY UZ
+	VA VC VE VG VI VN VU
+	WF WS
+	YE YT
+	ZA ZM ZW`))
+
+// Certifications are the age certifications given to the movies in the countries whose
+// rating system is known, from the least to the most restrictive.
+var Certifications = map[string][]string{
+	"AU": {"G", "PG", "M", "MA15+", "R18+", "X18+"},
+	"BR": {"L", "10", "12", "14", "16", "18"},
+	"DE": {"0", "6", "12", "16", "18"},
+	"ES": {"A", "7", "12", "16", "18"},
+	"FR": {"TP", "12", "16", "18"},
+	"GB": {"U", "PG", "12A", "12", "15", "18", "R18"},
+	"IT": {"T", "VM6", "VM14", "VM18"},
+	"JP": {"G", "PG12", "R15+", "R18+"},
+	"US": {"G", "PG", "PG-13", "R", "NC-17"},
+}
+
+// makeSet returns the set of the given values.
+func makeSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+
+	return set
+}
+
+// NormalizeCountry returns the canonical form of a country code, e.g. "IT" for " it".
+func NormalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+// NormalizeCertification returns the canonical form of a certification, e.g. "PG-13" for
+// "pg-13".
+func NormalizeCertification(certification string) string {
+	return strings.ToUpper(strings.TrimSpace(certification))
+}
+
+// IsCountry checks if a normalized code is an ISO 3166-1 alpha-2 country code.
+func IsCountry(country string) bool {
+	return countryCodes[country]
+}
+
+// CertificationsUpTo returns the certifications of a country not more restrictive than the
+// given one, e.g. G, PG and PG-13 for PG-13 in the US. It returns nil if the certification
+// is not in the list of the country, or if the list of the country is not known.
+func CertificationsUpTo(country, certification string) []string {
+	for i, value := range Certifications[country] {
+		if value == certification {
+			return Certifications[country][:i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Custom error for a person who is already credited in a movie with the same role.
+var ErrDuplicateCredit = errors.New("duplicate credit")
+
+// CreditRoles are the roles a person can be credited with, in the order they are listed.
+var CreditRoles = []string{"director", "writer", "actor", "composer"}
+
+// Credit is a struct that represents the role of a person in a movie.
+type Credit struct {
+	ID        int64   `json:"id"`
+	MovieID   int64   `json:"movie_id"`
+	PersonID  int64   `json:"person_id"`
+	Person    *Person `json:"person,omitempty"`
+	Role      string  `json:"role"`
+	Character string  `json:"character,omitempty"` // Only for actors
+}
+
+// ValidateCredit validates a credit by checking its fields.
+func ValidateCredit(v *validator.Validator, credit *Credit) {
+	v.Check(credit.PersonID > 0, "person_id", "must be provided")
+	v.Check(credit.Role != "", "role", "must be provided")
+	v.Check(validator.PermittedValue(credit.Role, CreditRoles...), "role", "must be one of "+strings.Join(CreditRoles, ", "))
+	v.Check(credit.Character == "" || credit.Role == "actor", "character", "must be provided only for actors")
+	v.Check(len(credit.Character) <= 500, "character", "must not be more than 500 bytes long")
+}
+
+// Interface for the credit model.
+type CreditModelInterface interface {
+	Insert(credit *Credit) error
+	GetAllForMovie(movieID int64) ([]*Credit, error)
+	GetAllForMovies(movieIDs []int64) (map[int64][]*Credit, error)
+	Delete(movieID int64, id int64) error
+}
+
+// Credit model struct that wraps a db connection pool.
+type CreditModel struct {
+	DB *sql.DB
+}
+
+// Insert is a method used to credit a person in a movie.
+func (m *CreditModel) Insert(credit *Credit) error {
+	// Only movies that are not in the trash can be credited.
+	query := `INSERT INTO credits (movie_id, person_id, role, character)
+			  SELECT id, $2, $3, $4 FROM movies
+			  WHERE id = $1 AND deleted_at IS NULL
+			  RETURNING id`
+
+	args := []any{credit.MovieID, credit.PersonID, credit.Role, credit.Character}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&credit.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows), strings.Contains(err.Error(), "credits_person_id_fkey"):
+			return ErrRecordNotFound
+		case strings.Contains(err.Error(), "credits_movie_id_person_id_role_character_key"):
+			return ErrDuplicateCredit
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAllForMovie is a method that retrieves the credits of a movie, along with the
+// credited people, grouped by role.
+func (m *CreditModel) GetAllForMovie(movieID int64) ([]*Credit, error) {
+	credits, err := m.GetAllForMovies([]int64{movieID})
+	if err != nil {
+		return nil, err
+	}
+
+	if credits[movieID] == nil {
+		return []*Credit{}, nil
+	}
+
+	return credits[movieID], nil
+}
+
+// GetAllForMovies is a method that retrieves in a single query the credits of many movies,
+// grouped by role as in GetAllForMovie, mapped by movie ID. Movies without credits are
+// not in the map.
+func (m *CreditModel) GetAllForMovies(movieIDs []int64) (map[int64][]*Credit, error) {
+	query := `SELECT credits.id, credits.movie_id, credits.role, credits.character,
+					 people.id, people.created_at, people.name, people.birth_year, people.version
+			  FROM credits
+			  INNER JOIN people ON people.id = credits.person_id
+			  WHERE credits.movie_id = ANY($1)
+			  ORDER BY array_position($2::text[], credits.role), credits.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(movieIDs), pq.Array(CreditRoles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := map[int64][]*Credit{}
+	for rows.Next() {
+		credit := Credit{Person: &Person{}}
+
+		err := rows.Scan(
+			&credit.ID,
+			&credit.MovieID,
+			&credit.Role,
+			&credit.Character,
+			&credit.Person.ID,
+			&credit.Person.CreatedAt,
+			&credit.Person.Name,
+			&credit.Person.BirthYear,
+			&credit.Person.Version)
+		if err != nil {
+			return nil, err
+		}
+		credit.PersonID = credit.Person.ID
+		credits[credit.MovieID] = append(credits[credit.MovieID], &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+// Delete is a method used to remove a credit from a movie.
+func (m *CreditModel) Delete(movieID int64, id int64) error {
+	if movieID < 1 || id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM credits
+			  WHERE id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// dateLayout is the layout of a Date, both in JSON and in query strings.
+const dateLayout = "2006-01-02"
+
+// Define an error that our UnmarshalJSON() method can return if we're unable to parse
+// the JSON string as a date.
+var ErrInvalidDateFormat = errors.New("invalid date format")
+
+// Date is a calendar date, without time of day. It is encoded in JSON as a
+// "YYYY-MM-DD" string and stored in the database as a date column.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a "YYYY-MM-DD" string into a Date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, ErrInvalidDateFormat
+	}
+
+	return Date{t}, nil
+}
+
+// Today returns the current date.
+func Today() Date {
+	now := time.Now()
+	return Date{time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// String returns the date in the "YYYY-MM-DD" format.
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+// MarshalJSON returns the date as a "YYYY-MM-DD" JSON string.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON parses a "YYYY-MM-DD" JSON string. It needs a pointer receiver to
+// modify the date.
+func (d *Date) UnmarshalJSON(jsonValue []byte) error {
+	unquotedJSONValue, err := strconv.Unquote(string(jsonValue))
+	if err != nil {
+		return ErrInvalidDateFormat
+	}
+
+	date, err := ParseDate(unquotedJSONValue)
+	if err != nil {
+		return err
+	}
+
+	*d = date
+
+	return nil
+}
+
+// Scan implements the sql.Scanner interface, so that a date column can be read into a Date.
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a Date", src)
+	}
+
+	*d = Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface, so that a Date can be stored in a date column.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Test method used to test the JSON encoding and decoding of Date.
+func TestDateJSON(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		json          string
+		expectedError bool
+	}{
+		{"valid date", `"2024-05-01"`, false},
+		{"leap day", `"2024-02-29"`, false},
+		{"not a leap year", `"2023-02-29"`, true},
+		{"with time", `"2024-05-01T10:00:00Z"`, true},
+		{"wrong layout", `"01/05/2024"`, true},
+		{"not a string", `20240501`, true},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var d Date
+
+			err := json.Unmarshal([]byte(test.json), &d)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("got error %v, expected error %t", err, test.expectedError)
+			}
+
+			if err != nil {
+				return
+			}
+
+			js, err := json.Marshal(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(js) != test.json {
+				t.Errorf("got %s, expected %s", js, test.json)
+			}
+		})
+	}
+}
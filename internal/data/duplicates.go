@@ -0,0 +1,258 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxDuplicates is the maximum number of candidates returned by FindDuplicates.
+const maxDuplicates = 10
+
+// NormalizeTitle returns the form of a title used to detect the duplicate movies: titles
+// are compared ignoring case, spaces and punctuation, e.g. "spidermanhomecoming" for
+// "Spider-Man: Homecoming". It matches the expression of movies_normalized_title_idx.
+func NormalizeTitle(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, title)
+}
+
+// runtimeTolerance returns the maximum difference in minutes between the runtimes of two
+// duplicate movies: 10% of the runtime, at least 5 minutes, as the runtimes of different
+// cuts or sources of the same movie rarely match exactly.
+func runtimeTolerance(runtime Runtime) int32 {
+	return max(5, int32(runtime)/10)
+}
+
+// FindDuplicates is a method that retrieves the movies not in the trash which are likely
+// the same movie: same normalized title (original or localized), a release year differing
+// by one at most and a similar runtime. The closest runtimes come first.
+func (m *MovieModel) FindDuplicates(movie *Movie) ([]*Movie, error) {
+	columns, _ := (&Movie{}).scanTargets(nil)
+
+	query := fmt.Sprintf(`SELECT %s
+			  FROM movies
+			  WHERE deleted_at IS NULL AND id <> $1
+			  AND year BETWEEN $3 - 1 AND $3 + 1
+			  AND abs(runtime - $4) <= $5
+			  AND (regexp_replace(lower(title), '[^[:alnum:]]+', '', 'g') = $2
+			  	   OR id IN (SELECT movie_id FROM movie_titles
+			  				 WHERE regexp_replace(lower(title), '[^[:alnum:]]+', '', 'g') = $2))
+			  ORDER BY abs(runtime - $4), id
+			  LIMIT $6`, columns)
+
+	args := []any{movie.ID, NormalizeTitle(movie.Title), movie.Year, movie.Runtime, runtimeTolerance(movie.Runtime), maxDuplicates}
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+	for rows.Next() {
+		var movie Movie
+
+		_, dests := movie.scanTargets(nil)
+
+		err := rows.Scan(dests...)
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// Merge is a method that folds the source movie into the target one, within a single
+// transaction:
+//   - the credits, localized titles, releases, reviews, watchlist items, relations and
+//     collections of the source are moved to the target, except the ones the target
+//     already has (e.g. a review of the same user);
+//   - the external IDs and images the target lacks are taken from the source;
+//   - the source is deleted, leaving a redirect from its ID to the target, and the
+//     redirects to the source now lead to the target.
+//
+// The other fields of the target are kept. Both movies are locked by their version, so
+// ErrEditConflict is returned if either changed in the meantime.
+func (m *MovieModel) Merge(source, target *Movie, userID int64) error {
+	// The rows the target already has are left to the source, and deleted along with it.
+	moves := []string{
+		`INSERT INTO credits (movie_id, person_id, role, character)
+		 SELECT $2, person_id, role, character FROM credits WHERE movie_id = $1
+		 ON CONFLICT DO NOTHING`,
+		`INSERT INTO movie_titles (movie_id, locale, title)
+		 SELECT $2, locale, title FROM movie_titles WHERE movie_id = $1
+		 ON CONFLICT DO NOTHING`,
+		`INSERT INTO movie_releases (movie_id, country, date, type, certification)
+		 SELECT $2, country, date, type, certification FROM movie_releases WHERE movie_id = $1
+		 ON CONFLICT DO NOTHING`,
+		`UPDATE reviews SET movie_id = $2
+		 WHERE movie_id = $1 AND user_id NOT IN (SELECT user_id FROM reviews WHERE movie_id = $2)`,
+		`UPDATE watchlist_items SET movie_id = $2
+		 WHERE movie_id = $1 AND user_id NOT IN (SELECT user_id FROM watchlist_items WHERE movie_id = $2)`,
+		`INSERT INTO movie_relations (movie_id, related_id, type)
+		 SELECT $2, related_id, type FROM movie_relations WHERE movie_id = $1 AND related_id <> $2
+		 ON CONFLICT DO NOTHING`,
+		`INSERT INTO movie_relations (movie_id, related_id, type)
+		 SELECT movie_id, $2, type FROM movie_relations WHERE related_id = $1 AND movie_id <> $2
+		 ON CONFLICT DO NOTHING`,
+		`UPDATE collection_movies SET movie_id = $2
+		 WHERE movie_id = $1 AND collection_id NOT IN (SELECT collection_id FROM collection_movies WHERE movie_id = $2)`,
+		`UPDATE movie_redirects SET movie_id = $2 WHERE movie_id = $1`,
+	}
+
+	// Creates a context with a 5 seconds timeout, as several tables are updated.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range moves {
+		_, err = tx.ExecContext(ctx, query, source.ID, target.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The source keeps its place only in the collections of the target, so it is removed
+	// from them before being deleted.
+	err = removeFromCollections(ctx, tx, []int64{source.ID})
+	if err != nil {
+		return err
+	}
+
+	// Delete the source first, so that its external IDs can be moved to the target.
+	result, err := tx.ExecContext(ctx, `DELETE FROM movies WHERE id = $1 AND version = $2 AND deleted_at IS NULL`, source.ID, source.Version)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrEditConflict
+	}
+
+	query := `INSERT INTO movie_redirects (old_id, movie_id, user_id)
+			  VALUES ($1, $2, $3)`
+
+	_, err = tx.ExecContext(ctx, query, source.ID, target.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	// Fill the gaps of the target with the data of the source.
+	if target.ExternalIDs.IMDb == "" {
+		target.ExternalIDs.IMDb = source.ExternalIDs.IMDb
+	}
+	if target.ExternalIDs.TMDB == 0 {
+		target.ExternalIDs.TMDB = source.ExternalIDs.TMDB
+	}
+	if target.ExternalIDs.Wikidata == "" {
+		target.ExternalIDs.Wikidata = source.ExternalIDs.Wikidata
+	}
+	if target.Poster == nil {
+		target.Poster = source.Poster
+	}
+	if target.Backdrop == nil {
+		target.Backdrop = source.Backdrop
+	}
+
+	query = `UPDATE movies
+			 SET imdb_id = $1, tmdb_id = $2, wikidata_id = $3, poster = $4, backdrop = $5, version = version + 1
+			 WHERE id = $6 AND version = $7 AND deleted_at IS NULL
+			 RETURNING version`
+
+	args := []any{target.ExternalIDs.IMDb, target.ExternalIDs.TMDB, target.ExternalIDs.Wikidata,
+		imageKey(target.Poster), imageKey(target.Backdrop), target.ID, target.Version}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&target.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	// The moved reviews change the ratings of the target.
+	err = updateMovieRatings(ctx, tx, target.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT average_rating, ratings_count FROM movies WHERE id = $1`, target.ID).Scan(&target.AverageRating, &target.RatingsCount)
+	if err != nil {
+		return err
+	}
+
+	// Record the new version of the target.
+	err = insertMovieVersion(ctx, tx, target, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRedirect is a method that returns the ID of the movie a merged movie has been folded
+// into, or ErrRecordNotFound if no movie has been merged with the given ID.
+func (m *MovieModel) GetRedirect(id int64) (int64, error) {
+	query := `SELECT movie_id
+			  FROM movie_redirects
+			  WHERE old_id = $1`
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var movieID int64
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return movieID, nil
+}
+
+// imageKey returns the value of the column of an image, empty if there is no image.
+func imageKey(image *Image) string {
+	if image == nil {
+		return ""
+	}
+
+	return image.Key
+}
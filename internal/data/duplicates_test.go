@@ -0,0 +1,47 @@
+package data
+
+import "testing"
+
+// Test method used to test the normalization of the titles compared by the duplicate check.
+func TestNormalizeTitle(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		title         string
+		expectedTitle string
+	}{
+		{"Alien", "alien"},
+		{"Spider-Man: Homecoming", "spidermanhomecoming"},
+		{"  Lord of the   Rings! ", "lordoftherings"},
+		{"Amélie", "amélie"},
+		{"2001: A Space Odyssey", "2001aspaceodyssey"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			if title := NormalizeTitle(test.title); title != test.expectedTitle {
+				t.Errorf("got %q, expected %q", title, test.expectedTitle)
+			}
+		})
+	}
+}
+
+// Test method used to test the tolerance on the runtimes of the duplicate movies.
+func TestRuntimeTolerance(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		runtime           Runtime
+		expectedTolerance int32
+	}{
+		{20, 5},
+		{90, 9},
+		{228, 22},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		if tolerance := runtimeTolerance(test.runtime); tolerance != test.expectedTolerance {
+			t.Errorf("got %d, expected %d", tolerance, test.expectedTolerance)
+		}
+	}
+}
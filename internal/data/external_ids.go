@@ -0,0 +1,96 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// ErrDuplicateExternalID is a custom error returned if an external ID of a movie is
+// already used by another movie.
+var ErrDuplicateExternalID = errors.New("duplicate external id")
+
+var (
+	// IMDbIDRX matches the IMDb IDs of the titles, e.g. "tt0111161".
+	IMDbIDRX = regexp.MustCompile(`^tt[0-9]{7,8}$`)
+
+	// WikidataIDRX matches the Wikidata IDs of the items, e.g. "Q172241".
+	WikidataIDRX = regexp.MustCompile(`^Q[1-9][0-9]*$`)
+)
+
+// ExternalIDs is a struct that holds the IDs of a movie in external catalogs. Each ID is
+// unique among the movies, and zero values mean that the ID is unknown.
+type ExternalIDs struct {
+	IMDb     string `json:"imdb,omitempty"`     // IMDb ID, e.g. "tt0111161"
+	TMDB     int64  `json:"tmdb,omitempty"`     // The Movie Database ID, e.g. 278
+	Wikidata string `json:"wikidata,omitempty"` // Wikidata item ID, e.g. "Q172241"
+}
+
+// ValidateExternalIDs validates the external IDs that have been set, the keys of the errors
+// being the names of the providers preceded by the prefix.
+func ValidateExternalIDs(v *validator.Validator, ids ExternalIDs, prefix string) {
+	if ids.IMDb != "" {
+		v.Check(validator.Matches(ids.IMDb, IMDbIDRX), prefix+"imdb", "must be a valid IMDb ID (e.g. tt0111161)")
+	}
+
+	v.Check(ids.TMDB >= 0, prefix+"tmdb", "must be a positive integer")
+
+	if ids.Wikidata != "" {
+		v.Check(validator.Matches(ids.Wikidata, WikidataIDRX), prefix+"wikidata", "must be a valid Wikidata ID (e.g. Q172241)")
+	}
+}
+
+// externalIDError returns ErrDuplicateExternalID if err is the violation of the unique
+// index of an external ID, err otherwise.
+func externalIDError(err error) error {
+	for _, index := range []string{"movies_imdb_id_key", "movies_tmdb_id_key", "movies_wikidata_id_key"} {
+		if strings.Contains(err.Error(), index) {
+			return ErrDuplicateExternalID
+		}
+	}
+
+	return err
+}
+
+// GetByExternalIDs is a method for fetching the record of the movies table matching all
+// the external IDs that have been set. If fields are given, only their columns are
+// selected (see scanTargets).
+func (m *MovieModel) GetByExternalIDs(ids ExternalIDs, fields ...string) (*Movie, error) {
+	// Zero values match every movie.
+	if ids == (ExternalIDs{}) {
+		return nil, ErrRecordNotFound
+	}
+
+	movie := Movie{}
+	columns, dests := movie.scanTargets(fields)
+
+	// The external IDs are unique, so at most one movie matches.
+	where, args := MovieCriteria{ExternalIDs: ids}.where()
+
+	query := fmt.Sprintf(`SELECT %s
+			  FROM movies
+			  %s
+			  LIMIT 1`, columns, where)
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(dests...)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &movie, nil
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Test method used to test the validation of the external IDs.
+func TestValidateExternalIDs(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		ids            ExternalIDs
+		expectedErrors []string
+	}{
+		{"no ids", ExternalIDs{}, []string{}},
+		{"valid ids", ExternalIDs{IMDb: "tt0111161", TMDB: 278, Wikidata: "Q172241"}, []string{}},
+		{"eight digits imdb id", ExternalIDs{IMDb: "tt10872600"}, []string{}},
+		{"invalid imdb id", ExternalIDs{IMDb: "nm0000151"}, []string{"external_ids.imdb"}},
+		{"negative tmdb id", ExternalIDs{TMDB: -1}, []string{"external_ids.tmdb"}},
+		{"invalid wikidata id", ExternalIDs{Wikidata: "Q0172241"}, []string{"external_ids.wikidata"}},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateExternalIDs(v, test.ids, "external_ids.")
+
+			keys := []string{}
+			for key := range v.Errors {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+
+			if !slices.Equal(keys, test.expectedErrors) {
+				t.Errorf("got %v, expected %v", keys, test.expectedErrors)
+			}
+		})
+	}
+}
+
+// Test method used to test the detection of the duplicate external IDs.
+func TestExternalIDError(t *testing.T) {
+	err := errors.New(`pq: duplicate key value violates unique constraint "movies_tmdb_id_key"`)
+	if !errors.Is(externalIDError(err), ErrDuplicateExternalID) {
+		t.Errorf("got %v, expected %v", externalIDError(err), ErrDuplicateExternalID)
+	}
+
+	err = errors.New("pq: connection refused")
+	if externalIDError(err) != err {
+		t.Errorf("got %v, expected %v", externalIDError(err), err)
+	}
+}
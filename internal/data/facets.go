@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FacetNames are the facets that can be computed over a movie listing.
+var FacetNames = []string{"genres", "decades", "runtimes"}
+
+// runtimeBuckets are the bounds, in minutes, of the buckets of the runtimes facet.
+var runtimeBuckets = []int{90, 120, 150}
+
+// FacetCount is a struct that holds the number of movies with a given value of a facet.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Facets is a struct that holds the counts of the movies of a listing, grouped by genre,
+// by decade of release and by runtime. Facets that have not been requested are nil.
+type Facets struct {
+	Genres   []FacetCount `json:"genres,omitempty"`   // Sorted by count, most common first
+	Decades  []FacetCount `json:"decades,omitempty"`  // e.g. "1990s", sorted by decade
+	Runtimes []FacetCount `json:"runtimes,omitempty"` // e.g. "90-119", sorted by runtime
+}
+
+// runtimeBucketLabel returns the label of a bucket of the runtimes facet, given its index
+// as returned by width_bucket().
+func runtimeBucketLabel(bucket string) string {
+	i, _ := strconv.Atoi(bucket)
+
+	switch {
+	case i <= 0:
+		return fmt.Sprintf("0-%d", runtimeBuckets[0]-1)
+	case i >= len(runtimeBuckets):
+		return fmt.Sprintf("%d+", runtimeBuckets[len(runtimeBuckets)-1])
+	default:
+		return fmt.Sprintf("%d-%d", runtimeBuckets[i-1], runtimeBuckets[i]-1)
+	}
+}
+
+// Facets is a method that counts the movies matching the criteria, the same selected by
+// GetAll, grouped by each of the requested facets (see FacetNames). All the counts are
+// taken from the same snapshot of the database.
+func (m *MovieModel) Facets(criteria MovieCriteria, names []string) (*Facets, error) {
+	where, args := criteria.where()
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// count runs a query returning a value and the number of movies with it in each row,
+	// labelling the values with the given function.
+	count := func(query string, label func(value string) string) ([]FacetCount, error) {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		counts := []FacetCount{}
+		for rows.Next() {
+			var fc FacetCount
+
+			err := rows.Scan(&fc.Value, &fc.Count)
+			if err != nil {
+				return nil, err
+			}
+			fc.Value = label(fc.Value)
+			counts = append(counts, fc)
+		}
+
+		return counts, rows.Err()
+	}
+
+	// Bounds of the runtime buckets, for the Postgres array literal.
+	bounds := make([]string, len(runtimeBuckets))
+	for i, bound := range runtimeBuckets {
+		bounds[i] = strconv.Itoa(bound)
+	}
+
+	facets := &Facets{}
+
+	for _, name := range names {
+		switch name {
+		case "genres":
+			facets.Genres, err = count(`SELECT genre, count(*)
+										FROM movies, unnest(genres) AS genre
+										`+where+`
+										GROUP BY genre
+										ORDER BY count(*) DESC, genre ASC`, func(value string) string { return value })
+		case "decades":
+			facets.Decades, err = count(`SELECT year / 10 * 10 AS decade, count(*)
+										 FROM movies
+										 `+where+`
+										 GROUP BY decade
+										 ORDER BY decade ASC`, func(value string) string { return value + "s" })
+		case "runtimes":
+			facets.Runtimes, err = count(`SELECT width_bucket(runtime, ARRAY[`+strings.Join(bounds, ", ")+`]) AS bucket, count(*)
+										  FROM movies
+										  `+where+`
+										  GROUP BY bucket
+										  ORDER BY bucket ASC`, runtimeBucketLabel)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,27 @@
+package data
+
+import "testing"
+
+// Test method used to test the labels of the runtime buckets.
+func TestRuntimeBucketLabel(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		bucket         string
+		expectedResult string
+	}{
+		{"0", "0-89"},
+		{"1", "90-119"},
+		{"2", "120-149"},
+		{"3", "150+"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.bucket, func(t *testing.T) {
+			result := runtimeBucketLabel(test.bucket)
+			if result != test.expectedResult {
+				t.Errorf("got %s, expected %s", result, test.expectedResult)
+			}
+		})
+	}
+}
@@ -1,17 +1,30 @@
 package data
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/AlessioPani/go-greenlight/internal/validator"
 )
 
+// ErrInvalidCursor is a custom error returned if a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // Filters is a struct that contains filters parameter of a request query.
+//
+// When Cursor is set, records are paged by keyset (sort column plus id) starting
+// right after the record the cursor points to, instead of using LIMIT/OFFSET.
+// SkipTotal lets clients opt out of counting the total number of records.
 type Filters struct {
 	Page         int
 	PageSize     int
 	Sort         string
 	SortSafeList []string
+	Cursor       string
+	SkipTotal    bool
 }
 
 // sortColumn checks if a column matches one of the entries of the safe list, and returns
@@ -44,6 +57,74 @@ func (f Filters) offset() int {
 	return (f.Page - 1) * f.PageSize
 }
 
+// cursor is the decoded form of an opaque pagination cursor. It holds the sort key it
+// was issued for, the value of the sort column and the id of the last record of a page.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// encodeCursor returns the opaque (base64url encoded) representation of a cursor.
+func encodeCursor(c cursor) string {
+	js, err := json.Marshal(c)
+	if err != nil {
+		// A cursor only contains strings and integers, so it can always be marshaled.
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// decodeCursor parses an opaque cursor, returning ErrInvalidCursor if it is malformed.
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+
+	js, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(js, &c)
+	if err != nil || c.Sort == "" || c.ID < 1 {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// keysetClause returns the SQL condition that selects the records placed after the
+// cursor, given the column matching the sort key and the placeholder numbers for the
+// sort value and the id. Records are always ordered by the sort column and then by
+// id ASC, so ties are broken by id.
+func (f Filters) keysetClause(column string, valuePlaceholder int, idPlaceholder int) string {
+	operator := ">"
+	if f.sortDirection() == "DESC" {
+		operator = "<"
+	}
+
+	return fmt.Sprintf("(%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND id > $%[4]d))", column, operator, valuePlaceholder, idPlaceholder)
+}
+
+// nextCursor returns the cursor pointing to a record, given the value of its sort column
+// and its id.
+func (f Filters) nextCursor(value string, id int64) string {
+	return encodeCursor(cursor{Sort: f.Sort, Value: value, ID: id})
+}
+
+// pageMetadata returns the pagination metadata of a page fetched with the filters, given
+// the total number of records matching them (ignored if SkipTotal is set).
+func (f Filters) pageMetadata(totalRecords int) Metadata {
+	switch {
+	case f.Cursor != "":
+		return Metadata{PageSize: f.PageSize, TotalRecords: totalRecords}
+	case f.SkipTotal:
+		return Metadata{CurrentPage: f.Page, PageSize: f.PageSize, FirstPage: 1}
+	default:
+		return calculateMetadata(totalRecords, f.Page, f.PageSize)
+	}
+}
+
 // ValidateFilters is an helper method to validate a Filters struct.
 func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(f.Page > 0, "page", "must be greater than zero")
@@ -51,15 +132,28 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
 	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
 	v.Check(validator.PermittedValue(f.Sort, f.SortSafeList...), "sort", "invalid sort value")
+
+	// Cursor
+	if f.Cursor != "" {
+		v.Check(f.Page == 1, "page", "must not be used together with a cursor")
+
+		c, err := decodeCursor(f.Cursor)
+		if err != nil {
+			v.AddError("cursor", "must be a valid cursor")
+			return
+		}
+		v.Check(c.Sort == f.Sort, "cursor", "does not match the sort parameter")
+	}
 }
 
 // Metadata is a struct that contains pagination metadata.
 type Metadata struct {
-	CurrentPage  int `json:"current_page,omitempty"`
-	PageSize     int `json:"page_size,omitempty"`
-	FirstPage    int `json:"first_page,omitempty"`
-	LastPage     int `json:"last_page,omitempty"`
-	TotalRecords int `json:"total_records,omitempty"`
+	CurrentPage  int    `json:"current_page,omitempty"`
+	PageSize     int    `json:"page_size,omitempty"`
+	FirstPage    int    `json:"first_page,omitempty"`
+	LastPage     int    `json:"last_page,omitempty"`
+	TotalRecords int    `json:"total_records,omitempty"`
+	NextCursor   string `json:"next_cursor,omitempty"`
 }
 
 // calculateMetadata is a function that calculates the pagination metadata,
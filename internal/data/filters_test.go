@@ -50,6 +50,10 @@ func TestValidateFilters(t *testing.T) {
 		{"page size not greater than 0", Filters{Page: 1, PageSize: 0, Sort: "title", SortSafeList: sortSafeList}, "page_size", "must be greater than zero"},
 		{"page size greater than 100", Filters{Page: 1, PageSize: 101, Sort: "title", SortSafeList: sortSafeList}, "page_size", "must be a maximum of 100"},
 		{"filter not supported", Filters{Page: 1, PageSize: 2, Sort: "wrong", SortSafeList: sortSafeList}, "sort", "invalid sort value"},
+		{"cursor ok", Filters{Page: 1, PageSize: 2, Sort: "title", SortSafeList: sortSafeList, Cursor: encodeCursor(cursor{Sort: "title", Value: "Alien", ID: 1})}, "cursor", ""},
+		{"cursor malformed", Filters{Page: 1, PageSize: 2, Sort: "title", SortSafeList: sortSafeList, Cursor: "wrong"}, "cursor", "must be a valid cursor"},
+		{"cursor sort mismatch", Filters{Page: 1, PageSize: 2, Sort: "year", SortSafeList: sortSafeList, Cursor: encodeCursor(cursor{Sort: "title", Value: "Alien", ID: 1})}, "cursor", "does not match the sort parameter"},
+		{"cursor with page", Filters{Page: 2, PageSize: 2, Sort: "title", SortSafeList: sortSafeList, Cursor: encodeCursor(cursor{Sort: "title", Value: "Alien", ID: 1})}, "page", "must not be used together with a cursor"},
 	}
 
 	// Executes tests.
@@ -165,3 +169,55 @@ func TestOffset(t *testing.T) {
 		})
 	}
 }
+
+// Test method used to test the encodeCursor and decodeCursor functions.
+func TestCursor(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		cursor        string
+		expectedValue cursor
+		expectedError error
+	}{
+		{"round trip", encodeCursor(cursor{Sort: "-year", Value: "2001", ID: 7}), cursor{Sort: "-year", Value: "2001", ID: 7}, nil},
+		{"not base64", "not a cursor!", cursor{}, ErrInvalidCursor},
+		{"not json", "bm90IGpzb24", cursor{}, ErrInvalidCursor},
+		{"missing id", encodeCursor(cursor{Sort: "id", Value: "1"}), cursor{}, ErrInvalidCursor},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := decodeCursor(test.cursor)
+			if err != test.expectedError {
+				t.Errorf("got error %v, expected %v", err, test.expectedError)
+			}
+			if result != test.expectedValue {
+				t.Errorf("got %+v, expected %+v", result, test.expectedValue)
+			}
+		})
+	}
+}
+
+// Test method used to test the keysetClause function.
+func TestKeysetClause(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		filter         Filters
+		expectedResult string
+	}{
+		{"asc", Filters{Sort: "title", SortSafeList: sortSafeList}, "(title > $5 OR (title = $5 AND id > $6))"},
+		{"desc", Filters{Sort: "-year", SortSafeList: sortSafeList}, "(year < $5 OR (year = $5 AND id > $6))"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.filter.keysetClause(test.filter.sortColumn(), 5, 6)
+			if result != test.expectedResult {
+				t.Errorf("got %s, expected %s", result, test.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Custom error for a genre whose slug already exists.
+var ErrDuplicateGenre = errors.New("duplicate genre")
+
+// SlugRX is the regular expression matched by the slug of a genre.
+var SlugRX = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+// Genre is a struct that represents a genre of the catalog. Movies are tagged with the
+// slug of the genre, while its aliases are the other names accepted for it.
+type Genre struct {
+	Slug       string   `json:"slug"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases"`
+	MovieCount int64    `json:"movie_count"` // Number of movies with the genre, not in the trash
+}
+
+// ValidateGenre validates a genre by checking its fields. The catalog, if not nil, is
+// used to check that the aliases are not already used by another genre.
+func ValidateGenre(v *validator.Validator, genre *Genre, catalog *GenreCatalog) {
+	v.Check(genre.Slug != "", "slug", "must be provided")
+	v.Check(len(genre.Slug) <= 50, "slug", "must not be more than 50 bytes long")
+	v.Check(validator.Matches(genre.Slug, SlugRX), "slug", "must contain only lowercase letters, digits and dashes")
+	if catalog != nil {
+		slug, ok := catalog.Lookup(genre.Slug)
+		v.Check(!ok || slug == genre.Slug, "slug", "must not be an alias of another genre")
+	}
+
+	v.Check(genre.Name != "", "name", "must be provided")
+	v.Check(len(genre.Name) <= 100, "name", "must not be more than 100 bytes long")
+
+	v.Check(len(genre.Aliases) <= 20, "aliases", "must not contain more than 20 aliases")
+	for _, alias := range genre.Aliases {
+		v.Check(genreKey(alias) != "", "aliases", "must not contain empty values")
+		v.Check(len(alias) <= 100, "aliases", "must not contain values more than 100 bytes long")
+
+		if catalog != nil {
+			slug, ok := catalog.Lookup(alias)
+			v.Check(!ok || slug == genre.Slug, "aliases", "must not contain names used by other genres")
+		}
+	}
+}
+
+// genreKey returns the key used to match a genre name against the catalog: names are
+// compared ignoring case, spaces, dashes and underscores.
+func genreKey(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// GenreCatalog maps the slugs and the aliases of the genres to their slugs.
+type GenreCatalog struct {
+	slugs map[string]string
+}
+
+// NewGenreCatalog returns the catalog of the given genres.
+func NewGenreCatalog(genres []*Genre) *GenreCatalog {
+	catalog := &GenreCatalog{slugs: make(map[string]string)}
+
+	for _, genre := range genres {
+		catalog.slugs[genreKey(genre.Slug)] = genre.Slug
+		for _, alias := range genre.Aliases {
+			catalog.slugs[genreKey(alias)] = genre.Slug
+		}
+	}
+
+	return catalog
+}
+
+// Lookup returns the slug of the genre matching a name, and whether the name is known.
+func (c *GenreCatalog) Lookup(name string) (string, bool) {
+	slug, ok := c.slugs[genreKey(name)]
+	return slug, ok
+}
+
+// Contains reports whether slug is the slug of a genre of the catalog.
+func (c *GenreCatalog) Contains(slug string) bool {
+	known, ok := c.slugs[genreKey(slug)]
+	return ok && known == slug
+}
+
+// Normalize returns the genres with the known names replaced by their slugs. Unknown names
+// are left untouched, so that ValidateMovie can report them.
+func (c *GenreCatalog) Normalize(genres []string) []string {
+	if genres == nil {
+		return nil
+	}
+
+	normalized := make([]string, len(genres))
+	for i, genre := range genres {
+		if slug, ok := c.Lookup(genre); ok {
+			normalized[i] = slug
+		} else {
+			normalized[i] = genre
+		}
+	}
+
+	return normalized
+}
+
+// uniqueGenres returns the genres without duplicates, keeping the first occurrence of each.
+func uniqueGenres(genres []string) []string {
+	unique := make([]string, 0, len(genres))
+	for _, genre := range genres {
+		if !slices.Contains(unique, genre) {
+			unique = append(unique, genre)
+		}
+	}
+
+	return unique
+}
+
+// Interface for the genre model.
+type GenreModelInterface interface {
+	Insert(genre *Genre) error
+	Get(slug string) (*Genre, error)
+	GetAll() ([]*Genre, error)
+	Update(genre *Genre) error
+	Catalog() (*GenreCatalog, error)
+	NormalizeMovies() (int64, error)
+}
+
+// Genre model struct that wraps a db connection pool.
+type GenreModel struct {
+	DB *sql.DB
+}
+
+// Insert is a method for adding a new genre to the catalog.
+func (m *GenreModel) Insert(genre *Genre) error {
+	query := `INSERT INTO genres (slug, name, aliases)
+			  VALUES ($1, $2, $3)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, genre.Slug, genre.Name, pq.Array(genre.Aliases))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "genres_pkey"):
+			return ErrDuplicateGenre
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get is a method for fetching a genre of the catalog by its slug, along with the number
+// of its movies.
+func (m *GenreModel) Get(slug string) (*Genre, error) {
+	query := `SELECT slug, name, aliases, (SELECT count(*) FROM movies WHERE genres @> ARRAY[slug] AND deleted_at IS NULL)
+			  FROM genres
+			  WHERE slug = $1`
+
+	var genre Genre
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, slug).Scan(&genre.Slug, &genre.Name, pq.Array(&genre.Aliases), &genre.MovieCount)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &genre, nil
+}
+
+// GetAll is a method that retrieves the whole catalog, sorted by slug, along with the
+// number of movies of each genre.
+func (m *GenreModel) GetAll() ([]*Genre, error) {
+	query := `SELECT slug, name, aliases, (SELECT count(*) FROM movies WHERE genres @> ARRAY[slug] AND deleted_at IS NULL)
+			  FROM genres
+			  ORDER BY slug ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []*Genre{}
+	for rows.Next() {
+		var genre Genre
+
+		err := rows.Scan(&genre.Slug, &genre.Name, pq.Array(&genre.Aliases), &genre.MovieCount)
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, &genre)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+// Update is a method for editing the name and the aliases of a genre. The slug can't be
+// changed, as it is stored in the movies.
+func (m *GenreModel) Update(genre *Genre) error {
+	query := `UPDATE genres
+			  SET name = $1, aliases = $2
+			  WHERE slug = $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, genre.Name, pq.Array(genre.Aliases), genre.Slug)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Catalog is a method that loads the catalog used to normalize and validate the genres
+// of the movies.
+func (m *GenreModel) Catalog() (*GenreCatalog, error) {
+	query := `SELECT slug, aliases FROM genres`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []*Genre{}
+	for rows.Next() {
+		var genre Genre
+
+		err := rows.Scan(&genre.Slug, pq.Array(&genre.Aliases))
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, &genre)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewGenreCatalog(genres), nil
+}
+
+// NormalizeMovies is a method that replaces the genres of the existing movies, trash
+// included, with their slugs, removing the duplicates. Each changed movie is saved as
+// a new version without author. Unknown genres are left untouched. It returns the number
+// of changed movies.
+func (m *GenreModel) NormalizeMovies() (int64, error) {
+	catalog, err := m.Catalog()
+	if err != nil {
+		return 0, err
+	}
+
+	// Creates a context with a 10 minutes timeout, as every movie is read.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Collect the changes first, as the rows can't be updated while they are read
+	// within the same transaction.
+	rows, err := tx.QueryContext(ctx, "SELECT id, genres FROM movies ORDER BY id FOR UPDATE")
+	if err != nil {
+		return 0, err
+	}
+
+	changes := map[int64][]string{}
+	for rows.Next() {
+		var id int64
+		var genres []string
+
+		err := rows.Scan(&id, pq.Array(&genres))
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		normalized := uniqueGenres(catalog.Normalize(genres))
+
+		if !slices.Equal(genres, normalized) {
+			changes[id] = normalized
+		}
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	updateQuery := `UPDATE movies
+					SET genres = $1, version = version + 1
+					WHERE id = $2`
+
+	versionQuery := `INSERT INTO movie_versions (movie_id, version, user_id, title, year, runtime, genres)
+					 SELECT id, version, NULL, title, year, runtime, genres FROM movies
+					 WHERE id = $1`
+
+	for id, genres := range changes {
+		_, err = tx.ExecContext(ctx, updateQuery, pq.Array(genres), id)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, versionQuery, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(changes)), nil
+}
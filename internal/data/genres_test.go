@@ -0,0 +1,60 @@
+package data
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test method used to test the normalization of genres through the catalog.
+func TestGenreCatalogNormalize(t *testing.T) {
+	catalog := NewGenreCatalog([]*Genre{
+		{Slug: "drama", Aliases: []string{}},
+		{Slug: "film-noir", Aliases: []string{"noir"}},
+		{Slug: "science-fiction", Aliases: []string{"sci-fi", "scifi"}},
+	})
+
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		genres         []string
+		expectedResult []string
+	}{
+		{"slugs", []string{"drama", "film-noir"}, []string{"drama", "film-noir"}},
+		{"case", []string{"Drama"}, []string{"drama"}},
+		{"separators", []string{"Science Fiction", " film_noir "}, []string{"science-fiction", "film-noir"}},
+		{"aliases", []string{"Sci-Fi", "SCIFI", "noir"}, []string{"science-fiction", "science-fiction", "film-noir"}},
+		{"unknown genre", []string{"space opera"}, []string{"space opera"}},
+		{"nil genres", nil, nil},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := catalog.Normalize(test.genres)
+			if !slices.Equal(result, test.expectedResult) {
+				t.Errorf("got %v, expected %v", result, test.expectedResult)
+			}
+
+			for _, genre := range result {
+				_, known := catalog.Lookup(genre)
+				if known != catalog.Contains(genre) {
+					t.Errorf("got Contains(%q) = %t, expected %t", genre, !known, known)
+				}
+			}
+		})
+	}
+
+	if catalog.Contains("sci-fi") {
+		t.Error("an alias must not be reported as a slug of the catalog")
+	}
+}
+
+// Test method used to test the removal of duplicated genres.
+func TestUniqueGenres(t *testing.T) {
+	result := uniqueGenres([]string{"science-fiction", "horror", "science-fiction", "horror", "drama"})
+	expected := []string{"science-fiction", "horror", "drama"}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("got %v, expected %v", result, expected)
+	}
+}
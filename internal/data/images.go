@@ -0,0 +1,159 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ImageWidths are the kinds of images of a movie, along with the widths (in pixels) of
+// the thumbnails generated for each of them.
+var ImageWidths = map[string][]int{
+	"poster":   {92, 185, 342},
+	"backdrop": {300, 780},
+}
+
+// Image is a struct that represents an image of a movie. Its files are stored under the
+// key: the original image as "<key>/original.jpg" and the thumbnails as "<key>/w<width>.jpg".
+type Image struct {
+	Kind string // Kind of image, one of the keys of ImageWidths
+	Key  string // Storage key of the image, which changes each time the image is replaced
+}
+
+// File returns the storage key of the file of the image with the given size, "original"
+// or "w<width>".
+func (i *Image) File(size string) string {
+	return i.Key + "/" + size + ".jpg"
+}
+
+// Files returns the storage keys of the original image and of all its thumbnails.
+func (i *Image) Files() map[string]string {
+	files := map[string]string{"original": i.File("original")}
+	for _, width := range ImageWidths[i.Kind] {
+		size := fmt.Sprintf("w%d", width)
+		files[size] = i.File(size)
+	}
+
+	return files
+}
+
+// MarshalJSON returns the URLs of the image and of its thumbnails, e.g.
+// {"url": "/v1/images/movies/1/poster/3f2a.../original.jpg", "thumbnails": {"w92": "..."}}.
+func (i *Image) MarshalJSON() ([]byte, error) {
+	files := i.Files()
+
+	output := struct {
+		URL        string            `json:"url"`
+		Thumbnails map[string]string `json:"thumbnails"`
+	}{
+		URL:        "/v1/images/" + files["original"],
+		Thumbnails: make(map[string]string, len(files)-1),
+	}
+
+	for size, file := range files {
+		if size != "original" {
+			output.Thumbnails[size] = "/v1/images/" + file
+		}
+	}
+
+	return json.Marshal(output)
+}
+
+// imageColumn scans a column holding the storage key of an image of a movie, an empty
+// key meaning that the movie has no image of that kind.
+type imageColumn struct {
+	kind string
+	dest **Image
+}
+
+// Scan implements the sql.Scanner interface.
+func (c imageColumn) Scan(src any) error {
+	var key string
+
+	switch src := src.(type) {
+	case nil:
+	case string:
+		key = src
+	case []byte:
+		key = string(src)
+	default:
+		return fmt.Errorf("cannot scan %T into an image", src)
+	}
+
+	*c.dest = nil
+	if key != "" {
+		*c.dest = &Image{Kind: c.kind, Key: key}
+	}
+
+	return nil
+}
+
+// SetImage is a method for replacing (or removing, if image is nil) an image of a specific
+// record in the movies table. As for Update, the version of the movie is incremented and
+// an ErrEditConflict error is returned if it changed in the meantime.
+func (m *MovieModel) SetImage(movie *Movie, kind string, image *Image, userID int64) error {
+	// The column is picked from a fixed list, never from the input.
+	var column string
+	switch kind {
+	case "poster", "backdrop":
+		column = kind
+	default:
+		return fmt.Errorf("unknown image kind %q", kind)
+	}
+
+	key := ""
+	if image != nil {
+		key = image.Key
+	}
+
+	// SQL query for updating the image of a movie.
+	query := `UPDATE movies
+			  SET ` + column + ` = $1, version = version + 1
+			  WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+			  RETURNING version`
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, key, movie.ID, movie.Version).Scan(&movie.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	// Record the new version of the movie, so that the history keeps matching the version
+	// numbers.
+	err = insertMovieVersion(ctx, tx, movie, userID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "poster":
+		movie.Poster = image
+	case "backdrop":
+		movie.Backdrop = image
+	}
+
+	return nil
+}
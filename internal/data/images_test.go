@@ -0,0 +1,54 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Test method used to test the JSON representation of an image.
+func TestImageMarshalJSON(t *testing.T) {
+	image := &Image{Kind: "backdrop", Key: "movies/1/backdrop/abc"}
+
+	js, err := json.Marshal(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"url":"/v1/images/movies/1/backdrop/abc/original.jpg","thumbnails":{"w300":"/v1/images/movies/1/backdrop/abc/w300.jpg","w780":"/v1/images/movies/1/backdrop/abc/w780.jpg"}}`
+	if string(js) != expected {
+		t.Errorf("got %s, expected %s", js, expected)
+	}
+}
+
+// Test method used to test the scanning of the image columns.
+func TestImageColumnScan(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name        string
+		src         any
+		expectedKey string
+	}{
+		{"key", []byte("movies/1/poster/abc"), "movies/1/poster/abc"},
+		{"empty key", "", ""},
+		{"null", nil, ""},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movie := Movie{Poster: &Image{Kind: "poster", Key: "previous"}}
+
+			err := imageColumn{kind: "poster", dest: &movie.Poster}.Scan(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch {
+			case test.expectedKey == "" && movie.Poster != nil:
+				t.Errorf("got image %v, expected none", movie.Poster)
+			case test.expectedKey != "" && (movie.Poster == nil || movie.Poster.Key != test.expectedKey):
+				t.Errorf("got image %v, expected key %q", movie.Poster, test.expectedKey)
+			}
+		})
+	}
+}
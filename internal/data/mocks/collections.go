@@ -0,0 +1,137 @@
+package mocks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var ValidCollection = data.Collection{
+	ID:          1,
+	CreatedAt:   time.Now(),
+	Name:        "The Lord of the Rings",
+	Description: "The trilogy directed by Peter Jackson",
+	Version:     1,
+}
+
+var ConflictCollection = data.Collection{
+	ID:        2,
+	CreatedAt: time.Now(),
+	Name:      "Alien",
+	Version:   1,
+}
+
+type CollectionModel struct{}
+
+// Method used for mocking the Insert method for the Collection model.
+func (m *CollectionModel) Insert(collection *data.Collection) error {
+	collection.ID = 10
+	collection.CreatedAt = time.Now()
+	collection.Version = 1
+
+	return nil
+}
+
+// Method used for mocking the Get method for the Collection model.
+// ValidMovie is the only movie of ValidCollection.
+func (m *CollectionModel) Get(id int64) (*data.Collection, error) {
+	var collection data.Collection
+
+	switch id {
+	case 1:
+		collection = ValidCollection
+	case 2:
+		collection = ConflictCollection
+	case 4:
+		return nil, data.ErrRecordNotFound
+	default:
+		return nil, errors.New("server error")
+	}
+
+	collection.Movies = []*data.CollectionMovie{}
+	if collection.ID == ValidCollection.ID {
+		movie := ValidMovie
+		collection.Movies = append(collection.Movies, &data.CollectionMovie{CollectionID: collection.ID, MovieID: movie.ID, Movie: &movie, Position: 1})
+	}
+
+	return &collection, nil
+}
+
+// Method used for mocking the GetAll method for the Collection model.
+func (m *CollectionModel) GetAll(name string, filters data.Filters) ([]*data.Collection, data.Metadata, error) {
+	if filters.PageSize == 99 {
+		return nil, data.Metadata{}, errors.New("server error")
+	}
+
+	collection := ValidCollection
+
+	return []*data.Collection{&collection}, metadata, nil
+}
+
+// Method used for mocking the GetAllForMovie method for the Collection model.
+// ValidMovie is in ValidCollection, the other movies are in no collection.
+func (m *CollectionModel) GetAllForMovie(movieID int64) ([]*data.Collection, error) {
+	if movieID != ValidMovie.ID {
+		return []*data.Collection{}, nil
+	}
+
+	collection, err := m.Get(ValidCollection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*data.Collection{collection}, nil
+}
+
+// Method used for mocking the Update method for the Collection model.
+func (m *CollectionModel) Update(collection *data.Collection) error {
+	if collection.ID == ConflictCollection.ID {
+		return data.ErrEditConflict
+	}
+
+	collection.Version++
+
+	return nil
+}
+
+// Method used for mocking the Delete method for the Collection model.
+func (m *CollectionModel) Delete(id int64) error {
+	switch id {
+	case 3:
+		return errors.New("server error")
+	case 4:
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Method used for mocking the SetMovie method for the Collection model.
+// Movies not returned by MovieModel.Get don't exist, and the movies are appended to the
+// end of ValidCollection.
+func (m *CollectionModel) SetMovie(item *data.CollectionMovie) error {
+	switch item.MovieID {
+	case 1, 2:
+	case 3:
+		return errors.New("server error")
+	default:
+		return data.ErrRecordNotFound
+	}
+
+	if item.Position == 0 || item.Position > 2 {
+		item.Position = 2
+	}
+
+	return nil
+}
+
+// Method used for mocking the RemoveMovie method for the Collection model.
+// ValidMovie is the only movie of ValidCollection.
+func (m *CollectionModel) RemoveMovie(collectionID int64, movieID int64) error {
+	if collectionID != ValidCollection.ID || movieID != ValidMovie.ID {
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package mocks
+
+import (
+	"errors"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var DirectorCredit = data.Credit{
+	ID:       1,
+	MovieID:  1,
+	PersonID: 1,
+	Person:   &ValidPerson,
+	Role:     "director",
+}
+
+type CreditModel struct{}
+
+// Method used for mocking the Insert method for the Credit model.
+func (m *CreditModel) Insert(credit *data.Credit) error {
+	switch {
+	case credit.PersonID == ConflictPerson.ID:
+		return data.ErrDuplicateCredit
+	case credit.MovieID == 4:
+		return data.ErrRecordNotFound
+	case credit.MovieID == 5:
+		return errors.New("server error")
+	}
+
+	credit.ID = 10
+
+	return nil
+}
+
+// Method used for mocking the GetAllForMovie method for the Credit model.
+func (m *CreditModel) GetAllForMovie(movieID int64) ([]*data.Credit, error) {
+	if movieID == 3 {
+		return nil, errors.New("server error")
+	}
+
+	return []*data.Credit{&DirectorCredit}, nil
+}
+
+// Method used for mocking the GetAllForMovies method for the Credit model.
+func (m *CreditModel) GetAllForMovies(movieIDs []int64) (map[int64][]*data.Credit, error) {
+	credits := map[int64][]*data.Credit{}
+
+	for _, movieID := range movieIDs {
+		switch movieID {
+		case 3:
+			return nil, errors.New("server error")
+		case DirectorCredit.MovieID:
+			credits[movieID] = []*data.Credit{&DirectorCredit}
+		}
+	}
+
+	return credits, nil
+}
+
+// Method used for mocking the Delete method for the Credit model.
+func (m *CreditModel) Delete(movieID int64, id int64) error {
+	switch id {
+	case 3:
+		return errors.New("server error")
+	case 4:
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
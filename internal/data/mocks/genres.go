@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var DramaGenre = data.Genre{
+	Slug:       "drama",
+	Name:       "Drama",
+	Aliases:    []string{"dramas"},
+	MovieCount: 42,
+}
+
+// genres is the catalog used by the mocks, covering the genres of the mock movies.
+var genres = []*data.Genre{
+	{Slug: "action", Name: "Action", Aliases: []string{}},
+	{Slug: "crime", Name: "Crime", Aliases: []string{}},
+	&DramaGenre,
+	{Slug: "epic", Name: "Epic", Aliases: []string{}},
+	{Slug: "fantasy", Name: "Fantasy", Aliases: []string{}},
+	{Slug: "horror", Name: "Horror", Aliases: []string{}},
+	{Slug: "science-fiction", Name: "Science Fiction", Aliases: []string{"sci-fi", "scifi"}},
+}
+
+type GenreModel struct{}
+
+// Method used for mocking the Insert method for the Genre model.
+func (m *GenreModel) Insert(genre *data.Genre) error {
+	for _, existing := range genres {
+		if existing.Slug == genre.Slug {
+			return data.ErrDuplicateGenre
+		}
+	}
+
+	return nil
+}
+
+// Method used for mocking the Get method for the Genre model.
+func (m *GenreModel) Get(slug string) (*data.Genre, error) {
+	for _, genre := range genres {
+		if genre.Slug == slug {
+			g := *genre
+			return &g, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+// Method used for mocking the GetAll method for the Genre model.
+func (m *GenreModel) GetAll() ([]*data.Genre, error) {
+	return genres, nil
+}
+
+// Method used for mocking the Update method for the Genre model.
+func (m *GenreModel) Update(genre *data.Genre) error {
+	return nil
+}
+
+// Method used for mocking the Catalog method for the Genre model.
+func (m *GenreModel) Catalog() (*data.GenreCatalog, error) {
+	return data.NewGenreCatalog(genres), nil
+}
+
+// Method used for mocking the NormalizeMovies method for the Genre model.
+func (m *GenreModel) NormalizeMovies() (int64, error) {
+	return 0, nil
+}
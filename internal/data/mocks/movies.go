@@ -1,7 +1,9 @@
 package mocks
 
 import (
+	"context"
 	"errors"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +18,10 @@ var ValidMovie = data.Movie{
 	Runtime:   228,
 	Genres:    []string{"fantasy", "epic"},
 	Version:   1,
+	ExternalIDs: data.ExternalIDs{
+		IMDb: "tt0120737",
+		TMDB: 120,
+	},
 }
 
 var InvalidMovie = data.Movie{
@@ -36,6 +42,30 @@ var ErrorMovie = data.Movie{
 	Runtime:   162,
 	Genres:    []string{"action"},
 	Version:   1,
+	ExternalIDs: data.ExternalIDs{
+		IMDb: "tt0409459",
+	},
+}
+
+// MergedMovieID is the ID of a movie that has been merged into ValidMovie.
+const MergedMovieID = 7
+
+// DuplicateIMDbID is an IMDb ID that Insert and Update report as used by another movie.
+const DuplicateIMDbID = "tt0111161"
+
+var deletedAt = time.Now()
+var deletedBy = int64(2)
+
+var DeletedMovie = data.Movie{
+	ID:        6,
+	CreatedAt: time.Now(),
+	Title:     "Alien",
+	Year:      1979,
+	Runtime:   117,
+	Genres:    []string{"horror", "sci-fi"},
+	Version:   1,
+	DeletedAt: &deletedAt,
+	DeletedBy: &deletedBy,
 }
 
 var metadata = data.Metadata{
@@ -49,53 +79,260 @@ var metadata = data.Metadata{
 type MovieModel struct{}
 
 // Method used for mocking the Insert method for the Movie model.
-func (m *MovieModel) Insert(movie *data.Movie) error {
+func (m *MovieModel) Insert(movie *data.Movie, userID int64) error {
 	if movie.Title == ErrorMovie.Title {
 		return errors.New("db error")
 	}
 
+	if movie.ExternalIDs.IMDb == DuplicateIMDbID {
+		return data.ErrDuplicateExternalID
+	}
+
+	return nil
+}
+
+// Method used for mocking the InsertMany method for the Movie model.
+func (m *MovieModel) InsertMany(movies []*data.Movie, userID int64) error {
+	for i, movie := range movies {
+		if movie.Title == ErrorMovie.Title {
+			return errors.New("db error")
+		}
+		if movie.ExternalIDs.IMDb == DuplicateIMDbID {
+			return data.ErrDuplicateExternalID
+		}
+		movie.ID = int64(i + 1)
+	}
+
 	return nil
 }
 
 // Method used for mocking the Get method for the Movie model.
-func (m *MovieModel) Get(id int64) (*data.Movie, error) {
+// As the model, it returns a new record on every call, so that handlers editing it don't
+// change the movies of the mock.
+func (m *MovieModel) Get(id int64, fields ...string) (*data.Movie, error) {
+	var movie data.Movie
+
 	switch id {
 	case 1:
-		return &ValidMovie, nil
+		movie = ValidMovie
 	case 2:
-		return &InvalidMovie, nil
+		movie = InvalidMovie
 	case 3:
-		return &ErrorMovie, nil
-	case 4:
+		movie = ErrorMovie
+	case 4, MergedMovieID:
 		return nil, data.ErrRecordNotFound
 	default:
 		return nil, errors.New("server error")
 	}
+
+	movie.Genres = slices.Clone(movie.Genres)
+
+	return &movie, nil
+}
+
+// Method used for mocking the GetMany method for the Movie model.
+// The ID of ErrorMovie causes an error, IDs of movies not returned by Get are skipped.
+func (m *MovieModel) GetMany(ids []int64, fields ...string) ([]*data.Movie, error) {
+	movies := []*data.Movie{}
+
+	for _, id := range ids {
+		if id == ErrorMovie.ID {
+			return nil, errors.New("server error")
+		}
+
+		movie, err := m.Get(id, fields...)
+		if err != nil {
+			continue
+		}
+		movies = append(movies, movie)
+	}
+
+	return movies, nil
+}
+
+// Method used for mocking the GetByExternalIDs method for the Movie model.
+// The IMDb ID of ValidMovie matches it, the one of ErrorMovie causes an error.
+func (m *MovieModel) GetByExternalIDs(ids data.ExternalIDs, fields ...string) (*data.Movie, error) {
+	switch ids.IMDb {
+	case ValidMovie.ExternalIDs.IMDb:
+		return m.Get(ValidMovie.ID, fields...)
+	case ErrorMovie.ExternalIDs.IMDb:
+		return nil, errors.New("server error")
+	default:
+		return nil, data.ErrRecordNotFound
+	}
 }
 
 // Method used for mocking the GetAll method for the Movie model.
-func (m *MovieModel) GetAll(title string, genres []string, filters data.Filters) ([]*data.Movie, data.Metadata, error) {
-	if strings.Contains(strings.ToLower(ErrorMovie.Title), strings.ToLower(title)) {
+// As Get, it returns new records, so that handlers localizing them don't change the movies
+// of the mock.
+func (m *MovieModel) GetAll(criteria data.MovieCriteria, filters data.Filters, fields ...string) ([]*data.Movie, data.Metadata, error) {
+	if strings.Contains(strings.ToLower(ErrorMovie.Title), strings.ToLower(criteria.Title)) {
 		return nil, data.Metadata{}, errors.New("server error")
 	}
 
-	return []*data.Movie{&ValidMovie}, metadata, nil
+	movie := ValidMovie
+	movie.Genres = slices.Clone(movie.Genres)
+
+	return []*data.Movie{&movie}, metadata, nil
+}
+
+// Method used for mocking the Stream method for the Movie model.
+func (m *MovieModel) Stream(ctx context.Context, criteria data.MovieCriteria, fn func(movie *data.Movie) error) error {
+	if strings.Contains(strings.ToLower(ErrorMovie.Title), strings.ToLower(criteria.Title)) {
+		return errors.New("server error")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	movie := ValidMovie
+
+	return fn(&movie)
 }
 
 // Method used for mocking the Update method for the Movie model.
-func (m *MovieModel) Update(movie *data.Movie) error {
+func (m *MovieModel) Update(movie *data.Movie, userID int64) error {
+	if movie.ID == ErrorMovie.ID {
+		return data.ErrEditConflict
+	}
+
+	if movie.ExternalIDs.IMDb == DuplicateIMDbID {
+		return data.ErrDuplicateExternalID
+	}
+
+	return nil
+}
+
+// Method used for mocking the SetImage method for the Movie model.
+func (m *MovieModel) SetImage(movie *data.Movie, kind string, image *data.Image, userID int64) error {
 	if movie.ID == ErrorMovie.ID {
 		return data.ErrEditConflict
 	}
 
+	switch kind {
+	case "poster":
+		movie.Poster = image
+	case "backdrop":
+		movie.Backdrop = image
+	}
+	movie.Version++
+
 	return nil
 }
 
 // Method used for mocking the Delete method for the Movie model.
-func (m *MovieModel) Delete(id int64) error {
+func (m *MovieModel) Delete(id int64, version int32, userID int64) error {
 	if id == 4 {
 		return data.ErrRecordNotFound
 	}
 
+	if id == ErrorMovie.ID && version != 0 {
+		return data.ErrEditConflict
+	}
+
 	return nil
 }
+
+// Method used for mocking the FindDuplicates method for the Movie model.
+// ValidMovie is a duplicate of the movies with the same normalized title and year.
+func (m *MovieModel) FindDuplicates(movie *data.Movie) ([]*data.Movie, error) {
+	if movie.Title == ErrorMovie.Title {
+		return nil, errors.New("server error")
+	}
+
+	if data.NormalizeTitle(movie.Title) != data.NormalizeTitle(ValidMovie.Title) || movie.Year != ValidMovie.Year {
+		return []*data.Movie{}, nil
+	}
+
+	duplicate, err := m.Get(ValidMovie.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*data.Movie{duplicate}, nil
+}
+
+// Method used for mocking the Merge method for the Movie model.
+func (m *MovieModel) Merge(source, target *data.Movie, userID int64) error {
+	if source.ID == ErrorMovie.ID || target.ID == ErrorMovie.ID {
+		return data.ErrEditConflict
+	}
+
+	target.Version++
+
+	return nil
+}
+
+// Method used for mocking the GetRedirect method for the Movie model.
+func (m *MovieModel) GetRedirect(id int64) (int64, error) {
+	if id == MergedMovieID {
+		return ValidMovie.ID, nil
+	}
+
+	return 0, data.ErrRecordNotFound
+}
+
+// Method used for mocking the Facets method for the Movie model.
+func (m *MovieModel) Facets(criteria data.MovieCriteria, names []string) (*data.Facets, error) {
+	facets := &data.Facets{}
+
+	for _, name := range names {
+		switch name {
+		case "genres":
+			facets.Genres = []data.FacetCount{{Value: "epic", Count: 1}, {Value: "fantasy", Count: 1}}
+		case "decades":
+			facets.Decades = []data.FacetCount{{Value: "2000s", Count: 1}}
+		case "runtimes":
+			facets.Runtimes = []data.FacetCount{{Value: "150+", Count: 1}}
+		}
+	}
+
+	return facets, nil
+}
+
+// Method used for mocking the Suggest method for the Movie model.
+func (m *MovieModel) Suggest(prefix string, limit int) ([]*data.MovieSuggestion, error) {
+	if strings.HasPrefix(strings.ToLower(ErrorMovie.Title), prefix) {
+		return nil, errors.New("server error")
+	}
+
+	return []*data.MovieSuggestion{{ID: ValidMovie.ID, Title: ValidMovie.Title, Year: ValidMovie.Year}}, nil
+}
+
+// Method used for mocking the Similar method for the Movie model.
+// InvalidMovie is similar to ValidMovie, any other movie causes an error.
+func (m *MovieModel) Similar(movie *data.Movie) ([]data.MovieScore, error) {
+	if movie.ID != ValidMovie.ID {
+		return nil, errors.New("server error")
+	}
+
+	return []data.MovieScore{{MovieID: InvalidMovie.ID, Score: 0.75}}, nil
+}
+
+// Method used for mocking the GetAllDeleted method for the Movie model.
+func (m *MovieModel) GetAllDeleted(filters data.Filters) ([]*data.Movie, data.Metadata, error) {
+	if filters.PageSize == 99 {
+		return nil, data.Metadata{}, errors.New("server error")
+	}
+
+	return []*data.Movie{&DeletedMovie}, metadata, nil
+}
+
+// Method used for mocking the Restore method for the Movie model.
+func (m *MovieModel) Restore(id int64) error {
+	switch id {
+	case 1:
+		return nil
+	case 4:
+		return data.ErrRecordNotFound
+	default:
+		return errors.New("server error")
+	}
+}
+
+// Method used for mocking the Purge method for the Movie model.
+func (m *MovieModel) Purge(deletedBefore time.Time) (int64, error) {
+	return 1, nil
+}
@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var ValidPerson = data.Person{
+	ID:        1,
+	CreatedAt: time.Now(),
+	Name:      "Peter Jackson",
+	BirthYear: 1961,
+	Version:   1,
+}
+
+var ConflictPerson = data.Person{
+	ID:        2,
+	CreatedAt: time.Now(),
+	Name:      "Howard Shore",
+	Version:   1,
+}
+
+type PersonModel struct{}
+
+// Method used for mocking the Insert method for the Person model.
+func (m *PersonModel) Insert(person *data.Person) error {
+	person.ID = 10
+	person.CreatedAt = time.Now()
+	person.Version = 1
+
+	return nil
+}
+
+// Method used for mocking the Get method for the Person model.
+func (m *PersonModel) Get(id int64) (*data.Person, error) {
+	switch id {
+	case 1:
+		person := ValidPerson
+		return &person, nil
+	case 2:
+		person := ConflictPerson
+		return &person, nil
+	case 4:
+		return nil, data.ErrRecordNotFound
+	default:
+		return nil, errors.New("server error")
+	}
+}
+
+// Method used for mocking the GetAll method for the Person model.
+func (m *PersonModel) GetAll(name string, filters data.Filters) ([]*data.Person, data.Metadata, error) {
+	if filters.PageSize == 99 {
+		return nil, data.Metadata{}, errors.New("server error")
+	}
+
+	return []*data.Person{&ValidPerson}, metadata, nil
+}
+
+// Method used for mocking the Update method for the Person model.
+func (m *PersonModel) Update(person *data.Person) error {
+	if person.ID == ConflictPerson.ID {
+		return data.ErrEditConflict
+	}
+
+	person.Version++
+
+	return nil
+}
+
+// Method used for mocking the Delete method for the Person model.
+func (m *PersonModel) Delete(id int64) error {
+	switch id {
+	case 3:
+		return errors.New("server error")
+	case 4:
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 var movieReadPermission = data.Permissions{"movies:read"}
 var movieWritePermission = data.Permissions{"movies:read", "movies:write"}
+var movieAdminPermission = data.Permissions{"movies:read", "movies:write", "movies:admin"}
 
 type PermissionModel struct{}
 
@@ -15,6 +16,8 @@ func (p PermissionModel) GetAllForUser(userID int64) (data.Permissions, error) {
 		return movieReadPermission, nil
 	case 2:
 		return movieWritePermission, nil
+	case 4:
+		return movieAdminPermission, nil
 	default:
 		return nil, data.ErrRecordNotFound
 	}
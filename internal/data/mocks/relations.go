@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"errors"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+// ValidRelation makes InvalidMovie a sequel of ValidMovie.
+var ValidRelation = data.MovieRelation{
+	ID:        1,
+	MovieID:   2,
+	RelatedID: 1,
+	Type:      "sequel_of",
+}
+
+type MovieRelationModel struct{}
+
+// Method used for mocking the Insert method for the MovieRelation model.
+// ValidRelation already exists, in both directions.
+func (m *MovieRelationModel) Insert(relation *data.MovieRelation) error {
+	if relation.Type == ValidRelation.Type &&
+		(relation.MovieID == ValidRelation.MovieID && relation.RelatedID == ValidRelation.RelatedID ||
+			relation.MovieID == ValidRelation.RelatedID && relation.RelatedID == ValidRelation.MovieID) {
+		return data.ErrDuplicateRelation
+	}
+
+	relation.ID = 10
+
+	return nil
+}
+
+// Method used for mocking the GetAllForMovie method for the MovieRelation model.
+// The ID of ErrorMovie causes an error.
+func (m *MovieRelationModel) GetAllForMovie(movieID int64) ([]*data.MovieRelation, error) {
+	switch movieID {
+	case ValidRelation.MovieID, ValidRelation.RelatedID:
+		relation := ValidRelation
+		return []*data.MovieRelation{&relation}, nil
+	case ErrorMovie.ID:
+		return nil, errors.New("server error")
+	default:
+		return []*data.MovieRelation{}, nil
+	}
+}
+
+// Method used for mocking the Delete method for the MovieRelation model.
+func (m *MovieRelationModel) Delete(movieID int64, id int64) error {
+	if id != ValidRelation.ID || (movieID != ValidRelation.MovieID && movieID != ValidRelation.RelatedID) {
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
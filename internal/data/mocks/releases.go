@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var ValidRelease = data.Release{
+	ID:            1,
+	MovieID:       1,
+	Country:       "IT",
+	Date:          data.Date{Time: time.Date(2002, time.January, 18, 0, 0, 0, 0, time.UTC)},
+	Type:          "theatrical",
+	Certification: "T",
+}
+
+type ReleaseModel struct{}
+
+// Method used for mocking the Insert method for the Release model.
+// A release of the same type and country of ValidRelease is a duplicate.
+func (m *ReleaseModel) Insert(release *data.Release) error {
+	switch {
+	case release.Country == ValidRelease.Country && release.Type == ValidRelease.Type:
+		return data.ErrDuplicateRelease
+	case release.MovieID == 4:
+		return data.ErrRecordNotFound
+	case release.MovieID == 5:
+		return errors.New("server error")
+	}
+
+	release.ID = 10
+
+	return nil
+}
+
+// Method used for mocking the GetAllForMovie method for the Release model.
+func (m *ReleaseModel) GetAllForMovie(movieID int64) ([]*data.Release, error) {
+	if movieID == 3 {
+		return nil, errors.New("server error")
+	}
+
+	return []*data.Release{&ValidRelease}, nil
+}
+
+// Method used for mocking the Delete method for the Release model.
+func (m *ReleaseModel) Delete(movieID int64, id int64) error {
+	switch id {
+	case 3:
+		return errors.New("server error")
+	case 4:
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package mocks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var ValidReview = data.Review{
+	ID:        1,
+	CreatedAt: time.Now(),
+	MovieID:   1,
+	UserID:    2,
+	Rating:    8,
+	Body:      "A masterpiece.",
+	Version:   1,
+}
+
+var OtherUserReview = data.Review{
+	ID:        2,
+	CreatedAt: time.Now(),
+	MovieID:   1,
+	UserID:    1,
+	Rating:    6,
+	Version:   1,
+}
+
+var ConflictReview = data.Review{
+	ID:        3,
+	CreatedAt: time.Now(),
+	MovieID:   1,
+	UserID:    2,
+	Rating:    4,
+	Version:   1,
+}
+
+type ReviewModel struct{}
+
+// Method used for mocking the Insert method for the Review model.
+func (m *ReviewModel) Insert(review *data.Review) error {
+	switch review.MovieID {
+	case 3:
+		return data.ErrDuplicateReview
+	case 4:
+		return data.ErrRecordNotFound
+	case 5:
+		return errors.New("server error")
+	}
+
+	review.ID = 10
+	review.Version = 1
+
+	return nil
+}
+
+// Method used for mocking the Get method for the Review model.
+func (m *ReviewModel) Get(id int64) (*data.Review, error) {
+	switch id {
+	case 1:
+		review := ValidReview
+		return &review, nil
+	case 2:
+		review := OtherUserReview
+		return &review, nil
+	case 3:
+		review := ConflictReview
+		return &review, nil
+	case 4:
+		return nil, data.ErrRecordNotFound
+	default:
+		return nil, errors.New("server error")
+	}
+}
+
+// Method used for mocking the GetAllForMovie method for the Review model.
+func (m *ReviewModel) GetAllForMovie(movieID int64, filters data.Filters) ([]*data.Review, data.Metadata, error) {
+	if movieID == 3 {
+		return nil, data.Metadata{}, errors.New("server error")
+	}
+
+	return []*data.Review{&ValidReview}, metadata, nil
+}
+
+// Method used for mocking the Update method for the Review model.
+func (m *ReviewModel) Update(review *data.Review) error {
+	if review.ID == ConflictReview.ID {
+		return data.ErrEditConflict
+	}
+
+	review.Version++
+
+	return nil
+}
+
+// Method used for mocking the Delete method for the Review model.
+func (m *ReviewModel) Delete(id int64) error {
+	if id == 4 {
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
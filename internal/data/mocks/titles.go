@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"errors"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var ItalianTitle = data.MovieTitle{
+	MovieID: 1,
+	Locale:  "it",
+	Title:   "Il Signore degli Anelli",
+}
+
+var GermanTitle = data.MovieTitle{
+	MovieID: 1,
+	Locale:  "de",
+	Title:   "Der Herr der Ringe",
+}
+
+type MovieTitleModel struct{}
+
+// Method used for mocking the GetAllForMovie method for the MovieTitle model.
+func (m *MovieTitleModel) GetAllForMovie(movieID int64) ([]*data.MovieTitle, error) {
+	titles, err := m.GetAllForMovies([]int64{movieID})
+	if err != nil {
+		return nil, err
+	}
+
+	if titles[movieID] == nil {
+		return []*data.MovieTitle{}, nil
+	}
+
+	return titles[movieID], nil
+}
+
+// Method used for mocking the GetAllForMovies method for the MovieTitle model.
+func (m *MovieTitleModel) GetAllForMovies(movieIDs []int64) (map[int64][]*data.MovieTitle, error) {
+	titles := map[int64][]*data.MovieTitle{}
+
+	for _, id := range movieIDs {
+		switch id {
+		case 1:
+			titles[id] = []*data.MovieTitle{&GermanTitle, &ItalianTitle}
+		case 3:
+			return nil, errors.New("server error")
+		}
+	}
+
+	return titles, nil
+}
+
+// Method used for mocking the Upsert method for the MovieTitle model.
+func (m *MovieTitleModel) Upsert(movie *data.Movie, title *data.MovieTitle, userID int64) error {
+	if movie.ID == ErrorMovie.ID {
+		return data.ErrEditConflict
+	}
+
+	title.MovieID = movie.ID
+	movie.Version++
+
+	return nil
+}
+
+// Method used for mocking the Delete method for the MovieTitle model.
+func (m *MovieTitleModel) Delete(movie *data.Movie, locale string, userID int64) error {
+	switch {
+	case locale != ItalianTitle.Locale && locale != GermanTitle.Locale:
+		return data.ErrRecordNotFound
+	case movie.ID == ErrorMovie.ID:
+		return data.ErrEditConflict
+	}
+
+	movie.Version++
+
+	return nil
+}
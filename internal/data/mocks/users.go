@@ -33,6 +33,18 @@ var InvalidUser = data.User{
 	Version:   1,
 }
 
+var AdminUser = data.User{
+	ID:        4,
+	CreatedAt: time.Now(),
+	Name:      "Leon Kennedy",
+	Email:     "l.kennedy@mail.com",
+	Activated: true,
+	Version:   1,
+}
+
+// AdminToken is the authentication token of AdminUser.
+const AdminToken = "validtokenadminuser1234567"
+
 type UserModel struct{}
 
 func (u *UserModel) Insert(user *data.User) error {
@@ -72,6 +84,8 @@ func (u *UserModel) GetForToken(tokenScope string, tokenPlaintext string) (*data
 		return nil, data.ErrRecordNotFound
 	case "validtokeninvaliduser12345":
 		return &InvalidUser, nil
+	case AdminToken:
+		return &AdminUser, nil
 	}
 
 	switch tokenScope {
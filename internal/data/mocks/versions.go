@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var FirstMovieVersion = data.MovieVersion{
+	MovieID:   1,
+	Version:   1,
+	CreatedAt: time.Now(),
+	Title:     "The Lord of the Rings",
+	Year:      2001,
+	Runtime:   178,
+	Genres:    []string{"fantasy"},
+}
+
+var SecondMovieVersion = data.MovieVersion{
+	MovieID:   1,
+	Version:   2,
+	CreatedAt: time.Now(),
+	Title:     "Lord of the Rings",
+	Year:      2001,
+	Runtime:   228,
+	Genres:    []string{"fantasy", "epic"},
+}
+
+type MovieVersionModel struct{}
+
+// Method used for mocking the GetAllForMovie method for the MovieVersion model.
+func (m *MovieVersionModel) GetAllForMovie(movieID int64, filters data.Filters) ([]*data.MovieVersion, data.Metadata, error) {
+	switch movieID {
+	case 1:
+		return []*data.MovieVersion{&FirstMovieVersion, &SecondMovieVersion}, metadata, nil
+	case 4:
+		return []*data.MovieVersion{}, data.Metadata{}, nil
+	default:
+		return nil, data.Metadata{}, errors.New("server error")
+	}
+}
+
+// Method used for mocking the Get method for the MovieVersion model.
+func (m *MovieVersionModel) Get(movieID int64, version int32) (*data.MovieVersion, error) {
+	if movieID == 5 {
+		return nil, errors.New("server error")
+	}
+
+	if movieID != 1 && movieID != 3 {
+		return nil, data.ErrRecordNotFound
+	}
+
+	switch version {
+	case 1:
+		v := FirstMovieVersion
+		v.MovieID = movieID
+		return &v, nil
+	case 2:
+		v := SecondMovieVersion
+		v.MovieID = movieID
+		return &v, nil
+	default:
+		return nil, data.ErrRecordNotFound
+	}
+}
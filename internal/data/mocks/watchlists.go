@@ -0,0 +1,108 @@
+package mocks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/data"
+)
+
+var watchedOn = data.Date{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)}
+
+var ValidWatchlistItem = data.WatchlistItem{
+	UserID:   2,
+	MovieID:  1,
+	Position: 1,
+	AddedAt:  time.Now(),
+}
+
+var WatchedWatchlistItem = data.WatchlistItem{
+	UserID:    2,
+	MovieID:   2,
+	Position:  2,
+	AddedAt:   time.Now(),
+	WatchedOn: &watchedOn,
+}
+
+var ErrorWatchlistItem = data.WatchlistItem{
+	UserID:   2,
+	MovieID:  3,
+	Position: 3,
+	AddedAt:  time.Now(),
+}
+
+type WatchlistModel struct{}
+
+// Method used for mocking the Add method for the Watchlist model.
+func (m *WatchlistModel) Add(item *data.WatchlistItem) error {
+	switch item.MovieID {
+	case 2:
+		return data.ErrDuplicateWatchlistItem
+	case 3:
+		return errors.New("server error")
+	}
+
+	item.Position = 3
+	item.AddedAt = time.Now()
+
+	return nil
+}
+
+// Method used for mocking the Get method for the Watchlist model.
+func (m *WatchlistModel) Get(userID int64, movieID int64) (*data.WatchlistItem, error) {
+	switch movieID {
+	case 1:
+		item := ValidWatchlistItem
+		movie := ValidMovie
+		item.Movie = &movie
+		return &item, nil
+	case 2:
+		item := WatchedWatchlistItem
+		movie := InvalidMovie
+		item.Movie = &movie
+		return &item, nil
+	case 3:
+		item := ErrorWatchlistItem
+		movie := ErrorMovie
+		item.Movie = &movie
+		return &item, nil
+	case 4:
+		return nil, data.ErrRecordNotFound
+	default:
+		return nil, errors.New("server error")
+	}
+}
+
+// Method used for mocking the GetAllForUser method for the Watchlist model.
+func (m *WatchlistModel) GetAllForUser(userID int64, watched *bool, filters data.Filters) ([]*data.WatchlistItem, data.Metadata, error) {
+	if filters.PageSize == 99 {
+		return nil, data.Metadata{}, errors.New("server error")
+	}
+
+	if watched != nil && *watched {
+		return []*data.WatchlistItem{&WatchedWatchlistItem}, metadata, nil
+	}
+
+	return []*data.WatchlistItem{&ValidWatchlistItem}, metadata, nil
+}
+
+// Method used for mocking the Update method for the Watchlist model.
+func (m *WatchlistModel) Update(item *data.WatchlistItem) error {
+	if item.MovieID == ErrorWatchlistItem.MovieID {
+		return errors.New("server error")
+	}
+
+	return nil
+}
+
+// Method used for mocking the Remove method for the Watchlist model.
+func (m *WatchlistModel) Remove(userID int64, movieID int64) error {
+	switch movieID {
+	case 3:
+		return errors.New("server error")
+	case 4:
+		return data.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -17,18 +17,38 @@ var (
 
 // Models is a struct which wraps our models.
 type Models struct {
-	Movies      MovieModelInterface
-	Permissions PermissionModelInterface
-	Tokens      TokenModelInterface
-	Users       UserModelInterface
+	Collections    CollectionModelInterface
+	Credits        CreditModelInterface
+	Genres         GenreModelInterface
+	Movies         MovieModelInterface
+	MovieRelations MovieRelationModelInterface
+	MovieTitles    MovieTitleModelInterface
+	MovieVersions  MovieVersionModelInterface
+	People         PersonModelInterface
+	Permissions    PermissionModelInterface
+	Releases       ReleaseModelInterface
+	Reviews        ReviewModelInterface
+	Tokens         TokenModelInterface
+	Users          UserModelInterface
+	Watchlists     WatchlistModelInterface
 }
 
 // NewModels() method returns a Models struct containing the initialized MovieModel.
 func NewModels(db *sql.DB) Models {
 	return Models{
-		Movies:      &MovieModel{DB: db},
-		Permissions: PermissionModel{DB: db},
-		Tokens:      &TokenModel{DB: db},
-		Users:       &UserModel{DB: db},
+		Collections:    &CollectionModel{DB: db},
+		Credits:        &CreditModel{DB: db},
+		Genres:         &GenreModel{DB: db},
+		Movies:         &MovieModel{DB: db},
+		MovieRelations: &MovieRelationModel{DB: db},
+		MovieTitles:    &MovieTitleModel{DB: db},
+		MovieVersions:  &MovieVersionModel{DB: db},
+		People:         &PersonModel{DB: db},
+		Permissions:    PermissionModel{DB: db},
+		Releases:       &ReleaseModel{DB: db},
+		Reviews:        &ReviewModel{DB: db},
+		Tokens:         &TokenModel{DB: db},
+		Users:          &UserModel{DB: db},
+		Watchlists:     &WatchlistModel{DB: db},
 	}
 }
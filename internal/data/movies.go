@@ -3,9 +3,14 @@ package data
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/AlessioPani/go-greenlight/internal/validator"
 	"github.com/lib/pq"
@@ -13,22 +18,349 @@ import (
 
 // Struct that defines a movie object.
 type Movie struct {
-	ID        int64     `json:"id"`                // Unique integer ID for the movie
-	CreatedAt time.Time `json:"-"`                 // Timestamp for when the movie is added to our database
-	Title     string    `json:"title"`             // Movie title
-	Year      int32     `json:"year,omitempty"`    // Movie release year
-	Runtime   Runtime   `json:"runtime,omitempty"` // Movie runtime (in minutes)
-	Genres    []string  `json:"genres,omitempty"`  // Slices of genres of the movie (drama, comedy, etc..)
-	Version   int32     `json:"version"`           // The version number starts at 1 and will be incremented each time the movie information is updated
+	ID            int64       `json:"id"`                       // Unique integer ID for the movie
+	CreatedAt     time.Time   `json:"-"`                        // Timestamp for when the movie is added to our database
+	Title         string      `json:"title"`                    // Movie title, localized if a title matches the locale of the client
+	OriginalTitle string      `json:"original_title,omitempty"` // Original title of the movie, set only when Title is localized
+	TitleLocale   string      `json:"title_locale,omitempty"`   // Locale of Title, set only when Title is localized
+	Year          int32       `json:"year,omitempty"`           // Movie release year
+	Runtime       Runtime     `json:"runtime,omitempty"`        // Movie runtime (in minutes)
+	RuntimeFormat string      `json:"-"`                        // Format of Runtime in JSON, one of RuntimeFormats (legacy if empty)
+	Genres        []string    `json:"genres,omitempty"`         // Slices of genres of the movie (drama, comedy, etc..)
+	ExternalIDs   ExternalIDs `json:"external_ids"`             // IDs of the movie in external catalogs (IMDb, TMDB, Wikidata), omitted from JSON if none is set
+	Version       int32       `json:"version"`                  // The version number starts at 1 and will be incremented each time the movie information is updated
+	AverageRating float64     `json:"average_rating"`           // Average of the ratings given by the users, 0 if the movie has not been rated
+	RatingsCount  int32       `json:"ratings_count"`            // Number of ratings given by the users
+	Poster        *Image      `json:"poster,omitempty"`         // Poster of the movie, nil if it has not been uploaded
+	Backdrop      *Image      `json:"backdrop,omitempty"`       // Backdrop of the movie, nil if it has not been uploaded
+	DeletedAt     *time.Time  `json:"deleted_at,omitempty"`     // Timestamp for when the movie has been moved to the trash, nil if it has not
+	DeletedBy     *int64      `json:"deleted_by,omitempty"`     // ID of the user who moved the movie to the trash, nil if it has not
+	relevance     float64     // Negated relevance of the title to the searched one, set only when sorting by relevance
+}
+
+// MarshalJSON encodes the movie to JSON, with its runtime in RuntimeFormat and without
+// its external IDs if none has been set.
+func (movie Movie) MarshalJSON() ([]byte, error) {
+	// The movieJSON type has the fields of Movie but not its methods, so that it is
+	// encoded by the default encoder. The runtime and external IDs fields shadow the ones
+	// of the movie.
+	type movieJSON Movie
+
+	var runtime any
+	if movie.Runtime != 0 {
+		runtime = movie.Runtime.Format(movie.RuntimeFormat)
+	}
+
+	var externalIDs *ExternalIDs
+	if movie.ExternalIDs != (ExternalIDs{}) {
+		externalIDs = &movie.ExternalIDs
+	}
+
+	return json.Marshal(struct {
+		movieJSON
+		Runtime     any          `json:"runtime,omitempty"`
+		ExternalIDs *ExternalIDs `json:"external_ids,omitempty"`
+	}{movieJSON(movie), runtime, externalIDs})
+}
+
+// sortValue returns the value of a sortable column of the movie, formatted as a string
+// so that it can be stored in a pagination cursor.
+func (movie *Movie) sortValue(column string) string {
+	switch column {
+	case "title":
+		return movie.Title
+	case "year":
+		return strconv.FormatInt(int64(movie.Year), 10)
+	case "runtime":
+		return strconv.FormatInt(int64(movie.Runtime), 10)
+	case "average_rating":
+		return strconv.FormatFloat(movie.AverageRating, 'f', -1, 64)
+	case "relevance":
+		return strconv.FormatFloat(movie.relevance, 'f', -1, 64)
+	default:
+		return strconv.FormatInt(movie.ID, 10)
+	}
+}
+
+// MovieFields are the fields of a movie that can be selected with a sparse fieldset. They
+// are named after the columns of the movies table.
+var MovieFields = []string{"id", "title", "year", "runtime", "genres", "version", "average_rating", "ratings_count", "poster", "backdrop", "external_ids"}
+
+// ValidateMovieFields validates the fields of a sparse fieldset.
+func ValidateMovieFields(v *validator.Validator, fields []string) {
+	for _, field := range fields {
+		v.Check(validator.PermittedValue(field, MovieFields...), "fields", "must contain only "+strings.Join(MovieFields, ", "))
+	}
+}
+
+// scanTargets returns the columns of the movies table to select for a sparse fieldset,
+// along with the fields of the movie to scan them into. The id is always selected, and
+// every column is selected if no field is given. Fields are named after their columns,
+// except external_ids which selects the columns of all the providers.
+func (movie *Movie) scanTargets(fields []string) (string, []any) {
+	targets := []struct {
+		field  string
+		column string
+		dest   any
+	}{
+		{"id", "id", &movie.ID},
+		{"created_at", "created_at", &movie.CreatedAt},
+		{"title", "title", &movie.Title},
+		{"year", "year", &movie.Year},
+		{"runtime", "runtime", &movie.Runtime},
+		{"genres", "genres", pq.Array(&movie.Genres)},
+		{"version", "version", &movie.Version},
+		{"average_rating", "average_rating", &movie.AverageRating},
+		{"ratings_count", "ratings_count", &movie.RatingsCount},
+		{"poster", "poster", imageColumn{kind: "poster", dest: &movie.Poster}},
+		{"backdrop", "backdrop", imageColumn{kind: "backdrop", dest: &movie.Backdrop}},
+		{"external_ids", "imdb_id", &movie.ExternalIDs.IMDb},
+		{"external_ids", "tmdb_id", &movie.ExternalIDs.TMDB},
+		{"external_ids", "wikidata_id", &movie.ExternalIDs.Wikidata},
+	}
+
+	columns := []string{}
+	dests := []any{}
+	for _, target := range targets {
+		if len(fields) == 0 || target.field == "id" || slices.Contains(fields, target.field) {
+			columns = append(columns, target.column)
+			dests = append(dests, target.dest)
+		}
+	}
+
+	return strings.Join(columns, ", "), dests
+}
+
+// movieSortColumn returns the column of the movies table matching a sort key.
+func movieSortColumn(key string) string {
+	switch key {
+	case "rating":
+		return "average_rating"
+	default:
+		return key
+	}
 }
 
 // Interface for the movie model.
 type MovieModelInterface interface {
-	Insert(movie *Movie) error
-	Get(id int64) (*Movie, error)
-	GetAll(title string, genres []string, filters Filters) ([]*Movie, Metadata, error)
-	Update(movie *Movie) error
-	Delete(id int64) error
+	Insert(movie *Movie, userID int64) error
+	InsertMany(movies []*Movie, userID int64) error
+	Get(id int64, fields ...string) (*Movie, error)
+	GetByExternalIDs(ids ExternalIDs, fields ...string) (*Movie, error)
+	GetMany(ids []int64, fields ...string) ([]*Movie, error)
+	GetAll(criteria MovieCriteria, filters Filters, fields ...string) ([]*Movie, Metadata, error)
+	Stream(ctx context.Context, criteria MovieCriteria, fn func(movie *Movie) error) error
+	Update(movie *Movie, userID int64) error
+	SetImage(movie *Movie, kind string, image *Image, userID int64) error
+	Delete(id int64, version int32, userID int64) error
+	FindDuplicates(movie *Movie) ([]*Movie, error)
+	Merge(source, target *Movie, userID int64) error
+	GetRedirect(id int64) (int64, error)
+	Facets(criteria MovieCriteria, names []string) (*Facets, error)
+	Suggest(prefix string, limit int) ([]*MovieSuggestion, error)
+	Similar(movie *Movie) ([]MovieScore, error)
+	GetAllDeleted(filters Filters) ([]*Movie, Metadata, error)
+	Restore(id int64) error
+	Purge(deletedBefore time.Time) (int64, error)
+}
+
+// MovieCriteria holds the criteria used to select the movies when listing or exporting
+// them. Zero values match every movie.
+type MovieCriteria struct {
+	Title            string      // Words contained in the title, matched according to TitleMode
+	TitleMode        string      // "words" (default) or "fuzzy", which tolerates typos and partial words
+	Genres           []string    // Genres of the movie, matched according to GenreMode
+	GenreMode        string      // "all" (default), "any" or "none" of the genres
+	YearMin          int32       // Minimum release year, 0 if unbounded
+	YearMax          int32       // Maximum release year, 0 if unbounded
+	RuntimeMin       int32       // Minimum runtime in minutes, 0 if unbounded
+	RuntimeMax       int32       // Maximum runtime in minutes, 0 if unbounded
+	CreatedAfter     time.Time   // Movies added after this time, zero if unbounded
+	CreatedBefore    time.Time   // Movies added before this time, zero if unbounded
+	PersonID         int64       // Person credited in the movie
+	Role             string      // Role of the person in the movie, any role if empty
+	ExternalIDs      ExternalIDs // External IDs of the movie, zero values match every movie
+	ReleasedIn       string      // Country code where the movie has been released, any country if empty
+	CertificationMax string      // Most restrictive certification of the movie in ReleasedIn, any if empty
+}
+
+// GenreModes are the supported ways of matching the genres of MovieCriteria.
+var GenreModes = []string{"all", "any", "none"}
+
+// TitleModes are the supported ways of matching the title of MovieCriteria.
+var TitleModes = []string{"words", "fuzzy"}
+
+// ValidateMovieCriteria validates the criteria used to select the movies.
+func ValidateMovieCriteria(v *validator.Validator, criteria MovieCriteria) {
+	if criteria.TitleMode != "" {
+		v.Check(validator.PermittedValue(criteria.TitleMode, TitleModes...), "title_mode", "must be one of "+strings.Join(TitleModes, ", "))
+	}
+
+	if criteria.GenreMode != "" {
+		v.Check(validator.PermittedValue(criteria.GenreMode, GenreModes...), "genre_mode", "must be one of "+strings.Join(GenreModes, ", "))
+	}
+
+	v.Check(criteria.YearMin >= 0, "year_min", "must be a positive integer")
+	v.Check(criteria.YearMax >= 0, "year_max", "must be a positive integer")
+	if criteria.YearMin != 0 && criteria.YearMax != 0 {
+		v.Check(criteria.YearMin <= criteria.YearMax, "year_min", "must not be greater than year_max")
+	}
+
+	v.Check(criteria.RuntimeMin >= 0, "runtime_min", "must be a positive integer")
+	v.Check(criteria.RuntimeMax >= 0, "runtime_max", "must be a positive integer")
+	if criteria.RuntimeMin != 0 && criteria.RuntimeMax != 0 {
+		v.Check(criteria.RuntimeMin <= criteria.RuntimeMax, "runtime_min", "must not be greater than runtime_max")
+	}
+
+	if !criteria.CreatedAfter.IsZero() && !criteria.CreatedBefore.IsZero() {
+		v.Check(criteria.CreatedAfter.Before(criteria.CreatedBefore), "created_after", "must be before created_before")
+	}
+
+	v.Check(criteria.PersonID >= 0, "person", "must be a positive integer")
+	if criteria.Role != "" {
+		v.Check(criteria.PersonID != 0, "role", "must be used together with person")
+		v.Check(validator.PermittedValue(criteria.Role, CreditRoles...), "role", "must be one of "+strings.Join(CreditRoles, ", "))
+	}
+
+	ValidateExternalIDs(v, criteria.ExternalIDs, "")
+
+	if criteria.ReleasedIn != "" {
+		v.Check(IsCountry(criteria.ReleasedIn), "released_in", "must be an ISO 3166-1 alpha-2 country code")
+	}
+	if criteria.CertificationMax != "" {
+		v.Check(criteria.ReleasedIn != "", "certification_max", "must be used together with released_in")
+		v.Check(criteria.ReleasedIn == "" || CertificationsUpTo(criteria.ReleasedIn, criteria.CertificationMax) != nil,
+			"certification_max", "must be a certification of the country of released_in")
+	}
+}
+
+// where returns the WHERE clause selecting the movies that match the criteria and are not
+// in the trash, along with the values of its placeholders, numbered from $1. Conditions
+// are added only for the criteria that have been set, so that the planner can use the
+// matching indexes.
+func (c MovieCriteria) where() (string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []any{}
+
+	// add appends a condition, replacing each ? in it with the placeholder of the
+	// matching value.
+	add := func(condition string, values ...any) {
+		for _, value := range values {
+			args = append(args, value)
+			condition = strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	// Uses built-in functionality of Postgres to achieve full-text search with lexemes.
+	// The fuzzy mode matches the titles containing a word similar to the searched ones
+	// (pg_trgm) or words starting with them.
+	switch {
+	// The localized titles are searched too, the subqueries referring to the title of the
+	// movie_titles table.
+	case c.Title != "" && c.TitleMode == "fuzzy":
+		if prefixes := prefixQuery(c.Title); prefixes != "" {
+			add("(? <% title OR to_tsvector('simple', title) @@ to_tsquery('simple', ?) OR "+
+				"id IN (SELECT movie_id FROM movie_titles WHERE ? <% title OR to_tsvector('simple', title) @@ to_tsquery('simple', ?)))",
+				c.Title, prefixes, c.Title, prefixes)
+		} else {
+			add("(? <% title OR id IN (SELECT movie_id FROM movie_titles WHERE ? <% title))", c.Title, c.Title)
+		}
+	case c.Title != "":
+		add("(to_tsvector('simple', title) @@ plainto_tsquery('simple', ?) OR "+
+			"id IN (SELECT movie_id FROM movie_titles WHERE to_tsvector('simple', title) @@ plainto_tsquery('simple', ?)))",
+			c.Title, c.Title)
+	}
+
+	// The containment and overlap operators can both use the GIN index on genres.
+	if len(c.Genres) > 0 {
+		switch c.GenreMode {
+		case "any":
+			add("genres && ?", pq.Array(c.Genres))
+		case "none":
+			add("NOT genres && ?", pq.Array(c.Genres))
+		default:
+			add("genres @> ?", pq.Array(c.Genres))
+		}
+	}
+
+	if c.YearMin != 0 {
+		add("year >= ?", c.YearMin)
+	}
+	if c.YearMax != 0 {
+		add("year <= ?", c.YearMax)
+	}
+	if c.RuntimeMin != 0 {
+		add("runtime >= ?", c.RuntimeMin)
+	}
+	if c.RuntimeMax != 0 {
+		add("runtime <= ?", c.RuntimeMax)
+	}
+	if !c.CreatedAfter.IsZero() {
+		add("created_at > ?", c.CreatedAfter)
+	}
+	if !c.CreatedBefore.IsZero() {
+		add("created_at < ?", c.CreatedBefore)
+	}
+
+	if c.ExternalIDs.IMDb != "" {
+		add("imdb_id = ?", c.ExternalIDs.IMDb)
+	}
+	if c.ExternalIDs.TMDB != 0 {
+		add("tmdb_id = ?", c.ExternalIDs.TMDB)
+	}
+	if c.ExternalIDs.Wikidata != "" {
+		add("wikidata_id = ?", c.ExternalIDs.Wikidata)
+	}
+
+	switch {
+	case c.PersonID != 0 && c.Role != "":
+		add("id IN (SELECT movie_id FROM credits WHERE person_id = ? AND role = ?)", c.PersonID, c.Role)
+	case c.PersonID != 0:
+		add("id IN (SELECT movie_id FROM credits WHERE person_id = ?)", c.PersonID)
+	}
+
+	// The most restrictive certification of a movie in the country must not exceed the
+	// maximum one, i.e. all its certifications must be allowed. Movies without a
+	// certification don't match a maximum certification.
+	switch {
+	case c.ReleasedIn != "" && c.CertificationMax != "":
+		add("id IN (SELECT movie_id FROM movie_releases WHERE country = ? AND certification <> '' GROUP BY movie_id HAVING bool_and(certification = ANY(?)))",
+			c.ReleasedIn, pq.Array(CertificationsUpTo(c.ReleasedIn, c.CertificationMax)))
+	case c.ReleasedIn != "":
+		add("id IN (SELECT movie_id FROM movie_releases WHERE country = ?)", c.ReleasedIn)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// relevance returns the SQL expression ranking the titles against the searched one, given
+// the placeholder number of the title: the similarity in fuzzy mode, ts_rank otherwise.
+// A movie is ranked by its best matching title, original or localized.
+func (c MovieCriteria) relevance(placeholder int) string {
+	rank := "ts_rank(to_tsvector('simple', %s), plainto_tsquery('simple', $%d))"
+	if c.TitleMode == "fuzzy" {
+		rank = "word_similarity($%[2]d, %[1]s)"
+	}
+
+	// GREATEST ignores the NULL maximum of the movies without localized titles.
+	return fmt.Sprintf("GREATEST(%s, (SELECT max(%s) FROM movie_titles WHERE movie_titles.movie_id = movies.id))",
+		fmt.Sprintf(rank, "movies.title", placeholder), fmt.Sprintf(rank, "movie_titles.title", placeholder))
+}
+
+// prefixQuery returns a tsquery matching the titles with words starting with each word of
+// the search, e.g. "god:* & fath:*" for "God fath". Only letters and digits are kept, so
+// the result is always a valid tsquery. It is empty if the search has no words.
+func prefixQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
 }
 
 // Movie model struct that wraps a db connection pool.
@@ -36,54 +368,82 @@ type MovieModel struct {
 	DB *sql.DB
 }
 
-// Insert is a method for inserting a new record in the movies table.
-func (m *MovieModel) Insert(movie *Movie) error {
+// Insert is a method for inserting a new record in the movies table. The first version
+// of the movie is recorded along with the ID of the user who created it.
+func (m *MovieModel) Insert(movie *Movie, userID int64) error {
+	return m.InsertMany([]*Movie{movie}, userID)
+}
+
+// InsertMany is a method for inserting several records in the movies table within a
+// single transaction: either all the movies are inserted or none of them is.
+func (m *MovieModel) InsertMany(movies []*Movie, userID int64) error {
 	// SQL query for inserting a movie in the db and returning
 	// the system-generated data.
-	query := `INSERT INTO movies (title, year, runtime, genres)
-			  VALUES ($1, $2, $3, $4)
+	query := `INSERT INTO movies (title, year, runtime, genres, imdb_id, tmdb_id, wikidata_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
 			  RETURNING id, created_at, version`
 
-	// Values for the placeholders in the query.
-	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres)}
-
-	// Creates a context with a 3 seconds timeout.
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	// Creates a context with a timeout of 30 seconds, plus 30 seconds for every
+	// thousand movies, as an import can contain thousands of them and each movie is
+	// recorded along with its first version.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(1+len(movies)/1000)*30*time.Second)
 	defer cancel()
 
-	// Executes QueryRow in order to get the system-generated data and returns the error, if any.
-	return m.DB.QueryRowContext(ctx, query, args...).Scan(&movie.ID, &movie.CreatedAt, &movie.Version)
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Prepare the statement once and execute it for every movie.
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, movie := range movies {
+		args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres),
+			movie.ExternalIDs.IMDb, movie.ExternalIDs.TMDB, movie.ExternalIDs.Wikidata}
+
+		err = stmt.QueryRowContext(ctx, args...).Scan(&movie.ID, &movie.CreatedAt, &movie.Version)
+		if err != nil {
+			return externalIDError(err)
+		}
+
+		err = insertMovieVersion(ctx, tx, movie, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-// Get is a method for fetching a specific record from the movies table.
-func (m *MovieModel) Get(id int64) (*Movie, error) {
+// Get is a method for fetching a specific record from the movies table. If fields are
+// given, only their columns are selected (see scanTargets).
+func (m *MovieModel) Get(id int64, fields ...string) (*Movie, error) {
 	// Sanitize ID.
 	if id < 1 {
 		return nil, ErrRecordNotFound
 	}
 
+	// movie struct to be returned back, and the columns to scan into it.
+	movie := Movie{}
+	columns, dests := movie.scanTargets(fields)
+
 	// SQL query for getting a movie in the db by its ID.
-	query := `SELECT id, created_at, title, year, runtime, genres, version
+	query := fmt.Sprintf(`SELECT %s
 			  FROM movies
-			  WHERE id = $1`
-
-	// movie struct to be returned back.
-	movie := Movie{}
+			  WHERE id = $1 AND deleted_at IS NULL`, columns)
 
 	// Creates a context with a 3 seconds timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	// Executes QueryRow in order to get the record.
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&movie.ID,
-		&movie.CreatedAt,
-		&movie.Title,
-		&movie.Year,
-		&movie.Runtime,
-		pq.Array(&movie.Genres),
-		&movie.Version,
-	)
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(dests...)
 
 	// Checks for errors during Scan().
 	if err != nil {
@@ -98,23 +458,121 @@ func (m *MovieModel) Get(id int64) (*Movie, error) {
 	return &movie, nil
 }
 
+// GetMany is a method for fetching several records from the movies table in a single query.
+// The movies are returned in the order of the IDs, skipping the ones that don't exist, so
+// the IDs should not contain duplicates. If fields are given, only their columns are
+// selected (see scanTargets).
+func (m *MovieModel) GetMany(ids []int64, fields ...string) ([]*Movie, error) {
+	// The columns of scanTargets are not qualified, so the ordinality of the requested IDs
+	// is aliased to names that don't clash with them.
+	columns, _ := (&Movie{}).scanTargets(fields)
+
+	query := fmt.Sprintf(`SELECT %s
+			  FROM unnest($1::bigint[]) WITH ORDINALITY AS requested(movie_id, position)
+			  INNER JOIN movies ON movies.id = requested.movie_id
+			  WHERE movies.deleted_at IS NULL
+			  ORDER BY requested.position`, columns)
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Executes the query.
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+	for rows.Next() {
+		var movie Movie
+
+		_, dests := movie.scanTargets(fields)
+
+		err := rows.Scan(dests...)
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, &movie)
+	}
+
+	// Checks again for errors.
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
 // GetAll is a method that retrieve all movies from the DB.
-func (m *MovieModel) GetAll(title string, genres []string, filters Filters) ([]*Movie, Metadata, error) {
+//
+// Movies are paged by LIMIT/OFFSET, or by keyset when filters.Cursor is set. In both cases
+// one extra record is fetched to know whether a next page exists and to return its cursor.
+// If fields are given, only their columns and the sort column are selected.
+func (m *MovieModel) GetAll(criteria MovieCriteria, filters Filters, fields ...string) ([]*Movie, Metadata, error) {
+	// WHERE clause shared by the data and the count queries, and the values of its
+	// placeholders.
+	where, whereArgs := criteria.where()
+
+	// count(*) OVER() is computed after the WHERE clause, so in cursor mode it would only
+	// count the records after the cursor. In that case the total is counted separately.
+	countColumn := "count(*) OVER()"
+	if filters.SkipTotal || filters.Cursor != "" {
+		countColumn = "0"
+	}
+
+	// Column used to sort the movies, and values for the placeholders in the query: the
+	// ones of the WHERE clause come first, followed by limit and offset.
+	sortColumn := movieSortColumn(filters.sortColumn())
+	args := slices.Clone(whereArgs)
+
+	// The best matches come first when sorting by relevance. The rank is negated, so that
+	// the ascending order and the keyset condition work as for the other columns.
+	relevance := "0"
+	sortExpr := sortColumn
+	if sortColumn == "relevance" {
+		args = append(args, criteria.Title)
+		relevance = "-" + criteria.relevance(len(args))
+		sortExpr = relevance
+	}
+
+	n := len(args)
+	args = append(args, filters.limit()+1, filters.offset())
+
+	// The sort column is needed to build the cursor of the next page.
+	if len(fields) > 0 {
+		fields = append(slices.Clone(fields), sortColumn)
+	}
+	columns, _ := (&Movie{}).scanTargets(fields)
+
+	// Add the keyset condition if a cursor has been provided.
+	keyset := ""
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		keyset = "AND " + filters.keysetClause(sortExpr, n+3, n+4)
+		args[n+1] = 0
+		args = append(args, c.Value, c.ID)
+	}
+
 	// SQL query to retreive all movie records.
-	// Uses built-in functionality of Postgres to achieve full-text search with lexemes.
-	query := fmt.Sprintf(`SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, version
+	query := fmt.Sprintf(`SELECT %s, %s, %s
 			 	          FROM movies
-			  			  WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '')
-			  			  AND (genres @> $2 OR $2 ='{}')
+			  			  %s
+			  			  %s
 			  			  ORDER BY %s %s, id ASC
-			  			  LIMIT $3 OFFSET $4`, filters.sortColumn(), filters.sortDirection())
+			  			  LIMIT $%d OFFSET $%d`, countColumn, columns, relevance, where, keyset, sortExpr, filters.sortDirection(), n+1, n+2)
 
 	// Creates a context with a 3 seconds timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	// Executes the query.
-	rows, err := m.DB.QueryContext(ctx, query, title, pq.Array(genres), filters.limit(), filters.offset())
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, Metadata{}, err
 	}
@@ -126,15 +584,10 @@ func (m *MovieModel) GetAll(title string, genres []string, filters Filters) ([]*
 	for rows.Next() {
 		var movie Movie
 
-		err := rows.Scan(
-			&totalRecords,
-			&movie.ID,
-			&movie.CreatedAt,
-			&movie.Title,
-			&movie.Year,
-			&movie.Runtime,
-			pq.Array(&movie.Genres),
-			&movie.Version)
+		_, dests := movie.scanTargets(fields)
+		dests = append([]any{&totalRecords}, dests...)
+
+		err := rows.Scan(append(dests, &movie.relevance)...)
 		if err != nil {
 			return nil, Metadata{}, err
 		}
@@ -146,63 +599,158 @@ func (m *MovieModel) GetAll(title string, genres []string, filters Filters) ([]*
 		return nil, Metadata{}, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	// Count the total records matching the filters, if a cursor has been used.
+	if filters.Cursor != "" && !filters.SkipTotal {
+		err = m.DB.QueryRowContext(ctx, "SELECT count(*) FROM movies "+where, whereArgs...).Scan(&totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+	}
+
+	metadata := filters.pageMetadata(totalRecords)
+
+	// If the extra record has been fetched, drop it and return the cursor of the next page.
+	if len(movies) > filters.limit() {
+		movies = movies[:filters.limit()]
+		last := movies[len(movies)-1]
+		metadata.NextCursor = filters.nextCursor(last.sortValue(sortColumn), last.ID)
+	}
 
 	return movies, metadata, nil
 }
 
-// Update is a method for updating a specific record in the movies table.
+// Stream is a method that retrieves all the movies matching the criteria from the DB and
+// calls fn for each of them while reading the rows, without loading the whole result
+// into memory. It stops at the first error returned by fn, or when ctx is done, e.g.
+// because the client has gone away.
+func (m *MovieModel) Stream(ctx context.Context, criteria MovieCriteria, fn func(movie *Movie) error) error {
+	where, args := criteria.where()
+
+	// SQL query to retreive all movie records.
+	query := `SELECT id, created_at, title, year, runtime, genres, version, average_rating, ratings_count
+			  FROM movies
+			  ` + where + `
+			  ORDER BY id ASC`
+
+	// Limits the given context to 10 minutes, as the whole table can be streamed.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	// Executes the query.
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Scan rows one at a time and pass them to fn.
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingsCount)
+		if err != nil {
+			return err
+		}
+
+		err = fn(&movie)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Checks again for errors.
+	return rows.Err()
+}
+
+// Update is a method for updating a specific record in the movies table. The new
+// version of the movie is recorded along with the ID of the user who updated it.
 //
 // This method has an optimistic locking functionality to prevent data race
 // condition on an update for a specific record.
 // It updates a record only if the version of the record is the same at
 // the beginning of the request, otherwise returns an ErrEditConflict error.
-func (m *MovieModel) Update(movie *Movie) error {
+func (m *MovieModel) Update(movie *Movie, userID int64) error {
 	// SQL query for completely update a movie.
 	query := `UPDATE movies
-			  SET title = $1, year = $2, runtime = $3, genres = $4, version = version + 1
-			  WHERE id = $5 AND version = $6
+			  SET title = $1, year = $2, runtime = $3, genres = $4, imdb_id = $5, tmdb_id = $6, wikidata_id = $7,
+			      version = version + 1
+			  WHERE id = $8 AND version = $9 AND deleted_at IS NULL
 			  RETURNING version`
 
 	// Values for the placeholders in the query.
-	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.ID, movie.Version}
+	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres),
+		movie.ExternalIDs.IMDb, movie.ExternalIDs.TMDB, movie.ExternalIDs.Wikidata, movie.ID, movie.Version}
 
 	// Creates a context with a 3 seconds timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Executes QueryRow in order to get the system-generated data and returns the error, if any.
 	// If no row has been retrieved, return an Edit Conflict (data race condition) error.
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&movie.Version)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&movie.Version)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return ErrEditConflict
 		default:
-			return err
+			return externalIDError(err)
 		}
 	}
 
-	return nil
+	// Record the new version of the movie.
+	err = insertMovieVersion(ctx, tx, movie, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Delete is a method for deleting a specific record from the movies table.
-func (m *MovieModel) Delete(id int64) error {
+// Delete is a method for moving a specific record of the movies table to the trash.
+// The record is soft deleted, recording when and by which user, so that it can be
+// restored until it is purged. Its relations and its places in the collections are kept
+// for a restore, and the movies in the trash are skipped when reading them. If version is
+// not 0, the movie is deleted only if it is still at that version, otherwise an
+// ErrEditConflict error is returned.
+func (m *MovieModel) Delete(id int64, version int32, userID int64) error {
 	// Sanitize ID.
 	if id < 1 {
 		return ErrRecordNotFound
 	}
 
-	// SQL query for deleting a movie.
-	query := `DELETE FROM movies
-			  WHERE id = $1`
+	// SQL query for soft deleting a movie.
+	query := `UPDATE movies
+			  SET deleted_at = NOW(), deleted_by = $2
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	args := []any{id, userID}
+	if version != 0 {
+		query += " AND version = $3"
+		args = append(args, version)
+	}
 
 	// Creates a context with a 3 seconds timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	// Executes the query.
-	result, err := m.DB.ExecContext(ctx, query, id)
+	result, err := m.DB.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -214,16 +762,172 @@ func (m *MovieModel) Delete(id int64) error {
 		return err
 	}
 
-	// If no rows were affected, return an ErrRecordNotFound error.
+	// If no rows were affected, the movie doesn't exist or it has been changed since it
+	// was read at the expected version.
 	if rowsAffected == 0 {
+		if version != 0 {
+			return ErrEditConflict
+		}
 		return ErrRecordNotFound
 	}
 
 	return nil
 }
 
-// ValidateMovie is a method that validates input data.
-func ValidateMovie(v *validator.Validator, movie *Movie) {
+// GetAllDeleted is a method that retrieves all the movies in the trash from the DB.
+func (m *MovieModel) GetAllDeleted(filters Filters) ([]*Movie, Metadata, error) {
+	// SQL query to retreive all the soft deleted movie records.
+	query := fmt.Sprintf(`SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, version, average_rating, ratings_count, deleted_at, deleted_by
+			 	          FROM movies
+			  			  WHERE deleted_at IS NOT NULL
+			  			  ORDER BY %s %s, id ASC
+			  			  LIMIT $1 OFFSET $2`, filters.sortColumn(), filters.sortDirection())
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Executes the query.
+	rows, err := m.DB.QueryContext(ctx, query, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	// Scan rows to fill the result and metadata struct.
+	totalRecords := 0
+	movies := []*Movie{}
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&totalRecords,
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingsCount,
+			&movie.DeletedAt,
+			&movie.DeletedBy)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		movies = append(movies, &movie)
+	}
+
+	// Checks again for errors.
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return movies, metadata, nil
+}
+
+// Restore is a method for moving a specific record of the movies table out of the trash.
+// The data of the movie is left untouched, so its version doesn't change.
+func (m *MovieModel) Restore(id int64) error {
+	// Sanitize ID.
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	// SQL query for restoring a soft deleted movie.
+	query := `UPDATE movies
+			  SET deleted_at = NULL, deleted_by = NULL
+			  WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Executes the query.
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	// If no rows were affected, the movie doesn't exist or it is not in the trash.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Purge is a method for permanently deleting the records of the movies table that have
+// been moved to the trash before the given time. It returns the number of deleted records.
+func (m *MovieModel) Purge(deletedBefore time.Time) (int64, error) {
+	// SQL query for locking the movies to be purged.
+	query := `SELECT id FROM movies
+			  WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			  FOR UPDATE`
+
+	// Creates a context with a 30 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// The following movies of their collections are shifted back, the other rows of the
+	// movies (e.g. relations and credits) are deleted in cascade.
+	err = removeFromCollections(ctx, tx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM movies WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, tx.Commit()
+}
+
+// ValidateMovie is a method that validates input data. Genres must be slugs of the
+// catalog, so they should be normalized with GenreCatalog.Normalize first.
+func ValidateMovie(v *validator.Validator, movie *Movie, catalog *GenreCatalog) {
 	// Title
 	v.Check(movie.Title != "", "title", "must be provided")
 	v.Check(len(movie.Title) <= 500, "title", "must not be more than 500 bytes long")
@@ -242,4 +946,10 @@ func ValidateMovie(v *validator.Validator, movie *Movie) {
 	v.Check(len(movie.Genres) >= 1, "genres", "must be provided at least 1 genre")
 	v.Check(len(movie.Genres) <= 5, "genres", "must not contain more than 5 genres")
 	v.Check(validator.Unique(movie.Genres), "genres", "must contain only unique values")
+	for _, genre := range movie.Genres {
+		v.Check(catalog.Contains(genre), "genres", fmt.Sprintf("must contain only known genres (%q is unknown)", genre))
+	}
+
+	// External IDs
+	ValidateExternalIDs(v, movie.ExternalIDs, "external_ids.")
 }
@@ -0,0 +1,216 @@
+package data
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Test method used to test the sortValue method of Movie, used to build pagination cursors.
+func TestMovieSortValue(t *testing.T) {
+	movie := &Movie{ID: 7, Title: "Alien", Year: 1979, Runtime: 117, AverageRating: 7.25, relevance: -0.5}
+
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		sort           string
+		expectedResult string
+	}{
+		{"id", "id", "7"},
+		{"title", "title", "Alien"},
+		{"year", "-year", "1979"},
+		{"runtime", "runtime", "117"},
+		{"rating", "-rating", "7.25"},
+		{"relevance", "relevance", "-0.5"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filters := Filters{Sort: test.sort, SortSafeList: []string{test.sort}}
+
+			result := movie.sortValue(movieSortColumn(filters.sortColumn()))
+			if result != test.expectedResult {
+				t.Errorf("got %s, expected %s", result, test.expectedResult)
+			}
+		})
+	}
+}
+
+// Test method used to test the JSON representation of a movie.
+func TestMovieMarshalJSON(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name             string
+		movie            Movie
+		expectedContains string
+		expectedMissing  string
+	}{
+		{"legacy runtime", Movie{Title: "Alien", Runtime: 117}, `"runtime":"117 mins"`, `"external_ids"`},
+		{"runtime format", Movie{Title: "Alien", Runtime: 117, RuntimeFormat: RuntimeFormatISO8601}, `"runtime":"PT117M"`, `"runtime_format"`},
+		{"external ids", Movie{Title: "Alien", ExternalIDs: ExternalIDs{IMDb: "tt0078748"}}, `"external_ids":{"imdb":"tt0078748"}`, `"runtime"`},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			js, err := json.Marshal(test.movie)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(js), test.expectedContains) {
+				t.Errorf("expected %s to contain %s", js, test.expectedContains)
+			}
+
+			if strings.Contains(string(js), test.expectedMissing) {
+				t.Errorf("expected %s not to contain %s", js, test.expectedMissing)
+			}
+		})
+	}
+}
+
+// Test method used to test the columns selected for a sparse fieldset.
+func TestMovieScanTargets(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name            string
+		fields          []string
+		expectedColumns string
+	}{
+		{"all fields", nil, "id, created_at, title, year, runtime, genres, version, average_rating, ratings_count, poster, backdrop, imdb_id, tmdb_id, wikidata_id"},
+		{"external ids", []string{"external_ids"}, "id, imdb_id, tmdb_id, wikidata_id"},
+		{"some fields", []string{"year", "title"}, "id, title, year"},
+		{"id only", []string{"id"}, "id"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns, dests := (&Movie{}).scanTargets(test.fields)
+
+			if columns != test.expectedColumns {
+				t.Errorf("got %q, expected %q", columns, test.expectedColumns)
+			}
+
+			if len(dests) != len(strings.Split(columns, ", ")) {
+				t.Errorf("got %d destinations for columns %q", len(dests), columns)
+			}
+		})
+	}
+}
+
+// Test method used to test the WHERE clause built from the movie criteria.
+func TestMovieCriteriaWhere(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		criteria      MovieCriteria
+		expectedWhere string
+		expectedArgs  int
+	}{
+		{"no criteria", MovieCriteria{}, "WHERE deleted_at IS NULL", 0},
+		{"title", MovieCriteria{Title: "rings"}, "WHERE deleted_at IS NULL AND (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR id IN (SELECT movie_id FROM movie_titles WHERE to_tsvector('simple', title) @@ plainto_tsquery('simple', $2)))", 2},
+		{"genres", MovieCriteria{Genres: []string{"drama"}}, "WHERE deleted_at IS NULL AND genres @> $1", 1},
+		{"person", MovieCriteria{Title: "rings", PersonID: 1}, "WHERE deleted_at IS NULL AND (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR id IN (SELECT movie_id FROM movie_titles WHERE to_tsvector('simple', title) @@ plainto_tsquery('simple', $2))) AND id IN (SELECT movie_id FROM credits WHERE person_id = $3)", 3},
+		{"person and role", MovieCriteria{PersonID: 1, Role: "actor"}, "WHERE deleted_at IS NULL AND id IN (SELECT movie_id FROM credits WHERE person_id = $1 AND role = $2)", 2},
+		{"any genre", MovieCriteria{Genres: []string{"drama"}, GenreMode: "any"}, "WHERE deleted_at IS NULL AND genres && $1", 1},
+		{"no genre", MovieCriteria{Genres: []string{"drama"}, GenreMode: "none"}, "WHERE deleted_at IS NULL AND NOT genres && $1", 1},
+		{"genre mode without genres", MovieCriteria{GenreMode: "none"}, "WHERE deleted_at IS NULL", 0},
+		{"ranges", MovieCriteria{YearMin: 1990, YearMax: 2000, RuntimeMax: 120}, "WHERE deleted_at IS NULL AND year >= $1 AND year <= $2 AND runtime <= $3", 3},
+		{"created", MovieCriteria{CreatedAfter: time.Now().Add(-time.Hour), CreatedBefore: time.Now()}, "WHERE deleted_at IS NULL AND created_at > $1 AND created_at < $2", 2},
+		{"external ids", MovieCriteria{ExternalIDs: ExternalIDs{IMDb: "tt0111161", TMDB: 278}}, "WHERE deleted_at IS NULL AND imdb_id = $1 AND tmdb_id = $2", 2},
+		{"released in", MovieCriteria{ReleasedIn: "IT"}, "WHERE deleted_at IS NULL AND id IN (SELECT movie_id FROM movie_releases WHERE country = $1)", 1},
+		{"maximum certification", MovieCriteria{ReleasedIn: "US", CertificationMax: "PG-13"}, "WHERE deleted_at IS NULL AND id IN (SELECT movie_id FROM movie_releases WHERE country = $1 AND certification <> '' GROUP BY movie_id HAVING bool_and(certification = ANY($2)))", 2},
+		{"fuzzy title", MovieCriteria{Title: "godfater", TitleMode: "fuzzy"}, "WHERE deleted_at IS NULL AND ($1 <% title OR to_tsvector('simple', title) @@ to_tsquery('simple', $2) OR id IN (SELECT movie_id FROM movie_titles WHERE $3 <% title OR to_tsvector('simple', title) @@ to_tsquery('simple', $4)))", 4},
+		{"fuzzy title without words", MovieCriteria{Title: "?!", TitleMode: "fuzzy"}, "WHERE deleted_at IS NULL AND ($1 <% title OR id IN (SELECT movie_id FROM movie_titles WHERE $2 <% title))", 2},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			where, args := test.criteria.where()
+
+			if where != test.expectedWhere {
+				t.Errorf("got %q, expected %q", where, test.expectedWhere)
+			}
+
+			if len(args) != test.expectedArgs {
+				t.Errorf("got %d args, expected %d", len(args), test.expectedArgs)
+			}
+		})
+	}
+}
+
+// Test method used to test the tsquery built for the prefix matching of the fuzzy title search.
+func TestPrefixQuery(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		search         string
+		expectedResult string
+	}{
+		{"single word", "godf", "godf:*"},
+		{"many words", "The  Godf", "the:* & godf:*"},
+		{"punctuation", "Ocean's 11!", "ocean:* & s:* & 11:*"},
+		{"no words", "'&|!", ""},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := prefixQuery(test.search)
+			if result != test.expectedResult {
+				t.Errorf("got %q, expected %q", result, test.expectedResult)
+			}
+		})
+	}
+}
+
+// Test method used to test the validation of the movie criteria.
+func TestValidateMovieCriteria(t *testing.T) {
+	now := time.Now()
+
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		criteria      MovieCriteria
+		expectedField string
+	}{
+		{"no criteria", MovieCriteria{}, ""},
+		{"valid criteria", MovieCriteria{GenreMode: "any", YearMin: 1990, YearMax: 1990, RuntimeMin: 90, CreatedBefore: now}, ""},
+		{"invalid genre mode", MovieCriteria{GenreMode: "some"}, "genre_mode"},
+		{"fuzzy title mode", MovieCriteria{Title: "godfater", TitleMode: "fuzzy"}, ""},
+		{"invalid title mode", MovieCriteria{Title: "godfater", TitleMode: "exact"}, "title_mode"},
+		{"negative year", MovieCriteria{YearMax: -1}, "year_max"},
+		{"inverted years", MovieCriteria{YearMin: 2000, YearMax: 1990}, "year_min"},
+		{"inverted runtimes", MovieCriteria{RuntimeMin: 120, RuntimeMax: 90}, "runtime_min"},
+		{"inverted creation times", MovieCriteria{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)}, "created_after"},
+		{"role without person", MovieCriteria{Role: "actor"}, "role"},
+		{"invalid role", MovieCriteria{PersonID: 1, Role: "producer"}, "role"},
+		{"released in", MovieCriteria{ReleasedIn: "IT", CertificationMax: "VM14"}, ""},
+		{"invalid country", MovieCriteria{ReleasedIn: "XX"}, "released_in"},
+		{"certification without country", MovieCriteria{CertificationMax: "PG-13"}, "certification_max"},
+		{"certification of another country", MovieCriteria{ReleasedIn: "IT", CertificationMax: "PG-13"}, "certification_max"},
+		{"country without certifications", MovieCriteria{ReleasedIn: "SE", CertificationMax: "15"}, "certification_max"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateMovieCriteria(v, test.criteria)
+
+			if test.expectedField == "" && !v.Valid() {
+				t.Errorf("got errors %v, expected none", v.Errors)
+			}
+
+			if _, ok := v.Errors[test.expectedField]; test.expectedField != "" && !ok {
+				t.Errorf("got errors %v, expected an error for %s", v.Errors, test.expectedField)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Custom error for a relation that already exists between two movies, in either direction.
+var ErrDuplicateRelation = errors.New("duplicate relation")
+
+// RelationTypes are the types of the relations between movies.
+var RelationTypes = []string{"sequel_of", "remake_of", "spin_off_of"}
+
+// inverseRelationTypes maps the types of the relations to the types of the same relations
+// seen from the related movie (e.g. the original of a remake is "remade_as").
+var inverseRelationTypes = map[string]string{
+	"sequel_of":   "prequel_of",
+	"remake_of":   "remade_as",
+	"spin_off_of": "spun_off_as",
+}
+
+// MovieRelation is a struct that represents a typed relation between two movies, e.g. the
+// movie is a sequel of the related movie.
+type MovieRelation struct {
+	ID        int64  `json:"id"`
+	MovieID   int64  `json:"movie_id"`
+	RelatedID int64  `json:"related_id"`
+	Type      string `json:"type"`
+}
+
+// From returns the other movie of the relation and the type of the relation, as seen from
+// the movie with the given ID.
+func (relation *MovieRelation) From(movieID int64) (int64, string) {
+	if relation.MovieID == movieID {
+		return relation.RelatedID, relation.Type
+	}
+
+	return relation.MovieID, inverseRelationTypes[relation.Type]
+}
+
+// ValidateMovieRelation validates a relation by checking its fields.
+func ValidateMovieRelation(v *validator.Validator, relation *MovieRelation) {
+	v.Check(relation.RelatedID > 0, "related_id", "must be provided")
+	v.Check(relation.RelatedID != relation.MovieID, "related_id", "must be a different movie")
+	v.Check(relation.Type != "", "type", "must be provided")
+	v.Check(validator.PermittedValue(relation.Type, RelationTypes...), "type", "must be one of "+strings.Join(RelationTypes, ", "))
+}
+
+// Interface for the movie relation model.
+type MovieRelationModelInterface interface {
+	Insert(relation *MovieRelation) error
+	GetAllForMovie(movieID int64) ([]*MovieRelation, error)
+	Delete(movieID int64, id int64) error
+}
+
+// Movie relation model struct that wraps a db connection pool.
+type MovieRelationModel struct {
+	DB *sql.DB
+}
+
+// Insert is a method used to relate two movies. The same relation can't be added in both
+// directions (e.g. two movies can't be the sequel of each other), as enforced by the
+// movie_relations_pair_type_idx unique index.
+func (m *MovieRelationModel) Insert(relation *MovieRelation) error {
+	// Only movies that are not in the trash can be related.
+	query := `INSERT INTO movie_relations (movie_id, related_id, type)
+			  SELECT $1, $2, $3
+			  WHERE (SELECT count(*) FROM movies WHERE id IN ($1, $2) AND deleted_at IS NULL) = 2
+			  RETURNING id`
+
+	args := []any{relation.MovieID, relation.RelatedID, relation.Type}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&relation.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		case strings.Contains(err.Error(), "movie_relations_pair_type_idx"):
+			return ErrDuplicateRelation
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAllForMovie is a method that retrieves the relations of a movie in both directions,
+// sorted by type, skipping the ones with movies in the trash.
+func (m *MovieRelationModel) GetAllForMovie(movieID int64) ([]*MovieRelation, error) {
+	query := `SELECT movie_relations.id, movie_relations.movie_id, movie_relations.related_id, movie_relations.type
+			  FROM movie_relations
+			  INNER JOIN movies ON movies.id = CASE WHEN movie_relations.movie_id = $1 THEN movie_relations.related_id ELSE movie_relations.movie_id END
+			  WHERE (movie_relations.movie_id = $1 OR movie_relations.related_id = $1) AND movies.deleted_at IS NULL
+			  ORDER BY movie_relations.type ASC, movies.year ASC, movie_relations.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	relations := []*MovieRelation{}
+	for rows.Next() {
+		var relation MovieRelation
+
+		err := rows.Scan(&relation.ID, &relation.MovieID, &relation.RelatedID, &relation.Type)
+		if err != nil {
+			return nil, err
+		}
+		relations = append(relations, &relation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+// Delete is a method used to remove a relation of a movie, in either direction.
+func (m *MovieRelationModel) Delete(movieID int64, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM movie_relations
+			  WHERE id = $1 AND (movie_id = $2 OR related_id = $2)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
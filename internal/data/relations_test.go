@@ -0,0 +1,43 @@
+package data
+
+import "testing"
+
+// Test method used to test the relations as seen from either movie.
+func TestMovieRelationFrom(t *testing.T) {
+	relation := MovieRelation{ID: 1, MovieID: 2, RelatedID: 1, Type: "remake_of"}
+
+	// Tests to be run.
+	tests := []struct {
+		name            string
+		movieID         int64
+		expectedMovieID int64
+		expectedType    string
+	}{
+		{"from the movie", 2, 1, "remake_of"},
+		{"from the related movie", 1, 2, "remade_as"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movieID, relationType := relation.From(test.movieID)
+
+			if movieID != test.expectedMovieID {
+				t.Errorf("got %d, expected %d", movieID, test.expectedMovieID)
+			}
+
+			if relationType != test.expectedType {
+				t.Errorf("got %q, expected %q", relationType, test.expectedType)
+			}
+		})
+	}
+}
+
+// Test method used to check that every relation type has an inverse.
+func TestInverseRelationTypes(t *testing.T) {
+	for _, relationType := range RelationTypes {
+		if inverseRelationTypes[relationType] == "" {
+			t.Errorf("missing inverse of %q", relationType)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Custom error for a movie which already has a release of the same type in a country.
+var ErrDuplicateRelease = errors.New("duplicate release")
+
+// ReleaseTypes are the types of the releases of a movie.
+var ReleaseTypes = []string{"premiere", "theatrical", "digital", "physical", "tv"}
+
+// Release is a struct that represents the release of a movie in a country, e.g. its
+// theatrical release in Italy, along with the age certification given there.
+type Release struct {
+	ID            int64  `json:"id"`
+	MovieID       int64  `json:"movie_id"`
+	Country       string `json:"country"` // ISO 3166-1 alpha-2 code
+	Date          Date   `json:"date"`
+	Type          string `json:"type"`
+	Certification string `json:"certification,omitempty"` // Empty if unknown
+}
+
+// ValidateRelease validates a release, whose country and certification should be normalized
+// with NormalizeCountry and NormalizeCertification first. The certification must be in the
+// list of the country, if it is known.
+func ValidateRelease(v *validator.Validator, release *Release) {
+	v.Check(release.Country != "", "country", "must be provided")
+	v.Check(IsCountry(release.Country), "country", "must be an ISO 3166-1 alpha-2 country code")
+
+	v.Check(!release.Date.IsZero(), "date", "must be provided")
+	v.Check(release.Date.IsZero() || release.Date.Year() >= 1888, "date", "must not be before 1888")
+
+	v.Check(release.Type != "", "type", "must be provided")
+	v.Check(validator.PermittedValue(release.Type, ReleaseTypes...), "type", "must be one of "+strings.Join(ReleaseTypes, ", "))
+
+	if certifications, ok := Certifications[release.Country]; ok && release.Certification != "" {
+		v.Check(validator.PermittedValue(release.Certification, certifications...), "certification", "must be one of "+strings.Join(certifications, ", "))
+	}
+	v.Check(len(release.Certification) <= 10, "certification", "must not be more than 10 bytes long")
+}
+
+// Interface for the release model.
+type ReleaseModelInterface interface {
+	Insert(release *Release) error
+	GetAllForMovie(movieID int64) ([]*Release, error)
+	Delete(movieID int64, id int64) error
+}
+
+// Release model struct that wraps a db connection pool.
+type ReleaseModel struct {
+	DB *sql.DB
+}
+
+// Insert is a method used to add a release to a movie.
+func (m *ReleaseModel) Insert(release *Release) error {
+	// Only movies that are not in the trash can be released.
+	query := `INSERT INTO movie_releases (movie_id, country, date, type, certification)
+			  SELECT id, $2, $3, $4, $5 FROM movies
+			  WHERE id = $1 AND deleted_at IS NULL
+			  RETURNING id`
+
+	args := []any{release.MovieID, release.Country, release.Date, release.Type, release.Certification}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&release.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		case strings.Contains(err.Error(), "movie_releases_movie_id_country_type_key"):
+			return ErrDuplicateRelease
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAllForMovie is a method that retrieves the releases of a movie, sorted by country and
+// date.
+func (m *ReleaseModel) GetAllForMovie(movieID int64) ([]*Release, error) {
+	query := `SELECT id, movie_id, country, date, type, certification
+			  FROM movie_releases
+			  WHERE movie_id = $1
+			  ORDER BY country ASC, date ASC, id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	releases := []*Release{}
+	for rows.Next() {
+		var release Release
+
+		err := rows.Scan(&release.ID, &release.MovieID, &release.Country, &release.Date, &release.Type, &release.Certification)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, &release)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// Delete is a method used to remove a release from a movie.
+func (m *ReleaseModel) Delete(movieID int64, id int64) error {
+	if movieID < 1 || id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM movie_releases
+			  WHERE id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
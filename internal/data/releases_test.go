@@ -0,0 +1,75 @@
+package data
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Test method used to test the validation of the releases.
+func TestValidateRelease(t *testing.T) {
+	date := Date{time.Date(2002, time.January, 18, 0, 0, 0, 0, time.UTC)}
+
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		release       Release
+		expectedField string
+	}{
+		{"valid release", Release{Country: "IT", Date: date, Type: "theatrical", Certification: "VM14"}, ""},
+		{"without certification", Release{Country: "IT", Date: date, Type: "digital"}, ""},
+		{"country without certifications", Release{Country: "SE", Date: date, Type: "theatrical", Certification: "15"}, ""},
+		{"missing country", Release{Date: date, Type: "theatrical"}, "country"},
+		{"unknown country", Release{Country: "XX", Date: date, Type: "theatrical"}, "country"},
+		{"missing date", Release{Country: "IT", Type: "theatrical"}, "date"},
+		{"date too old", Release{Country: "IT", Date: Date{time.Date(1850, time.January, 1, 0, 0, 0, 0, time.UTC)}, Type: "theatrical"}, "date"},
+		{"invalid type", Release{Country: "IT", Date: date, Type: "streaming"}, "type"},
+		{"certification of another country", Release{Country: "IT", Date: date, Type: "theatrical", Certification: "PG-13"}, "certification"},
+		{"certification too long", Release{Country: "SE", Date: date, Type: "theatrical", Certification: "ALL AUDIENCES"}, "certification"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateRelease(v, &test.release)
+
+			if test.expectedField == "" && !v.Valid() {
+				t.Errorf("got errors %v, expected none", v.Errors)
+			}
+
+			if _, ok := v.Errors[test.expectedField]; test.expectedField != "" && !ok {
+				t.Errorf("got errors %v, expected an error for %s", v.Errors, test.expectedField)
+			}
+		})
+	}
+}
+
+// Test method used to test the certifications not more restrictive than a given one.
+func TestCertificationsUpTo(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		country        string
+		certification  string
+		expectedResult []string
+	}{
+		{"least restrictive", "US", "G", []string{"G"}},
+		{"in the middle", "US", "PG-13", []string{"G", "PG", "PG-13"}},
+		{"most restrictive", "IT", "VM18", []string{"T", "VM6", "VM14", "VM18"}},
+		{"certification of another country", "IT", "PG-13", nil},
+		{"country without certifications", "SE", "15", nil},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := CertificationsUpTo(test.country, test.certification)
+			if !slices.Equal(result, test.expectedResult) {
+				t.Errorf("got %v, expected %v", result, test.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// Custom error for a user who already reviewed a movie.
+var ErrDuplicateReview = errors.New("duplicate review")
+
+// Review is a struct that represents the rating, and optional review, given by a user
+// to a movie.
+type Review struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	MovieID   int64     `json:"movie_id"`
+	UserID    int64     `json:"user_id"`
+	Rating    int32     `json:"rating"`
+	Body      string    `json:"body,omitempty"`
+	Version   int32     `json:"version"`
+}
+
+// ValidateReview validates a review by checking its fields.
+func ValidateReview(v *validator.Validator, review *Review) {
+	v.Check(review.Rating >= 1, "rating", "must be at least 1")
+	v.Check(review.Rating <= 10, "rating", "must be at most 10")
+	v.Check(len(review.Body) <= 10_000, "body", "must not be more than 10000 bytes long")
+}
+
+// Interface for the review model.
+type ReviewModelInterface interface {
+	Insert(review *Review) error
+	Get(id int64) (*Review, error)
+	GetAllForMovie(movieID int64, filters Filters) ([]*Review, Metadata, error)
+	Update(review *Review) error
+	Delete(id int64) error
+}
+
+// Review model struct that wraps a db connection pool.
+type ReviewModel struct {
+	DB *sql.DB
+}
+
+// updateMovieRatings recomputes the rating aggregates of a movie, within the same
+// transaction that added, edited or deleted one of its reviews.
+func updateMovieRatings(ctx context.Context, tx *sql.Tx, movieID int64) error {
+	// Lock the movie first, so that the aggregates are computed by a statement started
+	// after the concurrent changes of its reviews have been committed. The lock must not
+	// conflict with the key share lock taken on the movie by the foreign key of the review.
+	_, err := tx.ExecContext(ctx, "SELECT id FROM movies WHERE id = $1 FOR NO KEY UPDATE", movieID)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE movies
+			  SET average_rating = ratings.average, ratings_count = ratings.count
+			  FROM (SELECT coalesce(round(avg(rating), 2), 0) AS average, count(*) AS count
+			  		FROM reviews
+			  		WHERE movie_id = $1) AS ratings
+			  WHERE movies.id = $1`
+
+	_, err = tx.ExecContext(ctx, query, movieID)
+	return err
+}
+
+// Insert is a method used to add a new review, updating the ratings of the movie.
+func (m *ReviewModel) Insert(review *Review) error {
+	// Only movies that are not in the trash can be reviewed.
+	query := `INSERT INTO reviews (movie_id, user_id, rating, body)
+			  SELECT id, $2, $3, $4 FROM movies
+			  WHERE id = $1 AND deleted_at IS NULL
+			  RETURNING id, created_at, version`
+
+	args := []any{review.MovieID, review.UserID, review.Rating, review.Body}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&review.ID, &review.CreatedAt, &review.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		case strings.Contains(err.Error(), "reviews_movie_id_user_id_key"):
+			return ErrDuplicateReview
+		default:
+			return err
+		}
+	}
+
+	err = updateMovieRatings(ctx, tx, review.MovieID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get is a method used to retrieve a review by its ID.
+func (m *ReviewModel) Get(id int64) (*Review, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT reviews.id, reviews.created_at, reviews.movie_id, reviews.user_id, reviews.rating, reviews.body, reviews.version
+			  FROM reviews
+			  INNER JOIN movies ON movies.id = reviews.movie_id
+			  WHERE reviews.id = $1 AND movies.deleted_at IS NULL`
+
+	var review Review
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&review.ID,
+		&review.CreatedAt,
+		&review.MovieID,
+		&review.UserID,
+		&review.Rating,
+		&review.Body,
+		&review.Version,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &review, nil
+}
+
+// GetAllForMovie is a method that retrieves the reviews of a movie from the DB.
+func (m *ReviewModel) GetAllForMovie(movieID int64, filters Filters) ([]*Review, Metadata, error) {
+	query := fmt.Sprintf(`SELECT count(*) OVER(), id, created_at, movie_id, user_id, rating, body, version
+			  FROM reviews
+			  WHERE movie_id = $1
+			  ORDER BY %s %s, id ASC
+			  LIMIT $2 OFFSET $3`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	reviews := []*Review{}
+	for rows.Next() {
+		var review Review
+
+		err := rows.Scan(
+			&totalRecords,
+			&review.ID,
+			&review.CreatedAt,
+			&review.MovieID,
+			&review.UserID,
+			&review.Rating,
+			&review.Body,
+			&review.Version)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return reviews, metadata, nil
+}
+
+// Update is a method used to edit a review, updating the ratings of the movie.
+// Like MovieModel.Update, it returns an ErrEditConflict error if the review has been
+// changed since it was read.
+func (m *ReviewModel) Update(review *Review) error {
+	query := `UPDATE reviews
+			  SET rating = $1, body = $2, version = version + 1
+			  WHERE id = $3 AND version = $4
+			  RETURNING version`
+
+	args := []any{review.Rating, review.Body, review.ID, review.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&review.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	err = updateMovieRatings(ctx, tx, review.MovieID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete is a method used to delete a review, updating the ratings of the movie.
+func (m *ReviewModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM reviews
+			  WHERE id = $1
+			  RETURNING movie_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var movieID int64
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(&movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	err = updateMovieRatings(ctx, tx, movieID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
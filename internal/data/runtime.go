@@ -3,8 +3,12 @@ package data
 import (
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
 )
 
 // Declare a custom Runtime type, which has the underlying type int32 (the same as our
@@ -15,6 +19,27 @@ type Runtime int32
 // or convert the JSON string successfully.
 var ErrInvalidRuntimeFormat = errors.New("invalid runtime format")
 
+// Formats of the runtimes in the JSON responses. RuntimeFormatMins is the legacy format
+// used by MarshalJSON, the others are applied by Format, e.g. when encoding a movie with
+// a RuntimeFormat.
+const (
+	RuntimeFormatMins    = "mins"    // "135 mins"
+	RuntimeFormatMinutes = "minutes" // 135
+	RuntimeFormatISO8601 = "iso8601" // "PT135M"
+)
+
+// RuntimeFormats are the supported formats of the runtimes in the JSON responses.
+var RuntimeFormats = []string{RuntimeFormatMins, RuntimeFormatMinutes, RuntimeFormatISO8601}
+
+// Patterns of the runtimes accepted by ParseRuntime, besides "<n> mins": numbers of minutes,
+// hours and minutes (e.g. "2h 15m", "2h" or "135m") and ISO 8601 durations (e.g. "PT2H15M"
+// or "PT135M"), lowercased.
+var (
+	digitsRX       = regexp.MustCompile(`^\d+$`)
+	hoursMinutesRX = regexp.MustCompile(`^(?:(\d+)\s*h)?\s*(?:(\d+)\s*m)?$`)
+	iso8601RX      = regexp.MustCompile(`^pt(?:(\d+)h)?(?:(\d+)m)?$`)
+)
+
 // Implement a MarshalJSON() method on the Runtime type so that it satisfies the
 // json.Marshaler interface. This should return the JSON-encoded value for the movie
 // runtime (in our case, it will return a string in the format "<runtime> mins").
@@ -31,36 +56,96 @@ func (r Runtime) MarshalJSON() ([]byte, error) {
 }
 
 // Implement a UnmarshalJSON() method on the Runtime type so that it satisfies the
-// json.Unmarshaler interface.
+// json.Unmarshaler interface. The runtime can be an integer number of minutes or any
+// string accepted by ParseRuntime.
 // IMPORTANT: Because UnmarshalJSON() needs to modify the receiver (our Runtime type),
 // we must use a pointer receiver for this to work correctly.
 // Otherwise, we will only be modifying a copy (which is then discarded when this method returns).
 func (r *Runtime) UnmarshalJSON(jsonValue []byte) error {
-	// We expect to receive a field like "<runtime> mins", so first of all
-	// we remove the quotes "".
+	// A JSON number is a plain number of minutes.
+	num, err := strconv.ParseInt(string(jsonValue), 10, 32)
+	if err == nil {
+		*r = Runtime(num)
+		return nil
+	}
+
+	// Otherwise we expect a string, so first of all we remove the quotes "".
 	// If no quotes are available, we raise an invalid runtime format error.
 	unquotedJSONValue, err := strconv.Unquote(string(jsonValue))
 	if err != nil {
 		return ErrInvalidRuntimeFormat
 	}
 
-	// Split the input to separate the number.
-	parts := strings.Split(unquotedJSONValue, " ")
+	runtime, err := ParseRuntime(unquotedJSONValue)
+	if err != nil {
+		return err
+	}
 
-	// Sanitize input by checking its format.
-	if len(parts) != 2 || parts[1] != "mins" {
-		return ErrInvalidRuntimeFormat
+	// If the input is in a valid format, assign the runtime to the receiver.
+	*r = runtime
+
+	return nil
+}
+
+// ParseRuntime parses a runtime written as "<n> mins", as a number of minutes, as hours and minutes (e.g. "2h 15m")
+// or as an ISO 8601 duration (e.g. "PT135M"), ignoring case and surrounding spaces. It
+// returns ErrInvalidRuntimeFormat if the runtime is written otherwise.
+func ParseRuntime(s string) (Runtime, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// Legacy format or plain number of minutes.
+	if number, ok := strings.CutSuffix(s, " mins"); ok || digitsRX.MatchString(s) {
+		num, err := strconv.ParseInt(number, 10, 32)
+		if err != nil {
+			return 0, ErrInvalidRuntimeFormat
+		}
+		return Runtime(num), nil
 	}
 
-	// Parse the number.
-	num, err := strconv.ParseInt(parts[0], 10, 32)
-	if err != nil {
-		return ErrInvalidRuntimeFormat
+	matches := iso8601RX.FindStringSubmatch(s)
+	if matches == nil {
+		matches = hoursMinutesRX.FindStringSubmatch(s)
+	}
+
+	// At least one of the hours and the minutes must be given.
+	if matches == nil || matches[1] == "" && matches[2] == "" {
+		return 0, ErrInvalidRuntimeFormat
 	}
 
-	// If the input is in the valid format, assign
-	// the number to the receiver.
-	*r = Runtime(num)
+	var minutes int64
+	for i, multiplier := range []int64{60, 1} {
+		if matches[i+1] == "" {
+			continue
+		}
 
-	return nil
+		num, err := strconv.ParseInt(matches[i+1], 10, 32)
+		if err != nil {
+			return 0, ErrInvalidRuntimeFormat
+		}
+		minutes += num * multiplier
+	}
+
+	if minutes > math.MaxInt32 {
+		return 0, ErrInvalidRuntimeFormat
+	}
+
+	return Runtime(minutes), nil
+}
+
+// Format returns the value of the runtime in one of the RuntimeFormats, to be encoded to
+// JSON.
+func (r Runtime) Format(format string) any {
+	switch format {
+	case RuntimeFormatMinutes:
+		return int32(r)
+	case RuntimeFormatISO8601:
+		return fmt.Sprintf("PT%dM", r)
+	default:
+		return fmt.Sprintf("%d mins", r)
+	}
+}
+
+// ValidateRuntimeFormat validates the format of the runtimes chosen by a client.
+func ValidateRuntimeFormat(v *validator.Validator, format string) {
+	v.Check(validator.PermittedValue(format, RuntimeFormats...), "runtime_format", "must be one of "+strings.Join(RuntimeFormats, ", "))
 }
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test method used to test the decoding of the runtimes.
+func TestRuntimeUnmarshalJSON(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name            string
+		json            string
+		expectedRuntime Runtime
+		expectedError   error
+	}{
+		{"legacy format", `"135 mins"`, 135, nil},
+		{"integer", `135`, 135, nil},
+		{"number of minutes", `"135"`, 135, nil},
+		{"hours and minutes", `"2h 15m"`, 135, nil},
+		{"hours and minutes without space", `"2h15m"`, 135, nil},
+		{"only hours", `"2h"`, 120, nil},
+		{"only minutes", `"135m"`, 135, nil},
+		{"iso 8601 minutes", `"PT135M"`, 135, nil},
+		{"iso 8601 hours and minutes", `"PT2H15M"`, 135, nil},
+		{"case insensitive", `" pt2h15m "`, 135, nil},
+		{"float", `135.5`, 0, ErrInvalidRuntimeFormat},
+		{"unknown unit", `"135 secs"`, 0, ErrInvalidRuntimeFormat},
+		{"iso 8601 seconds", `"PT2H15M30S"`, 0, ErrInvalidRuntimeFormat},
+		{"empty duration", `"PT"`, 0, ErrInvalidRuntimeFormat},
+		{"empty string", `""`, 0, ErrInvalidRuntimeFormat},
+		{"overflow", `"99999999999h"`, 0, ErrInvalidRuntimeFormat},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var runtime Runtime
+
+			err := runtime.UnmarshalJSON([]byte(test.json))
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedError)
+			}
+
+			if runtime != test.expectedRuntime {
+				t.Errorf("got %d, expected %d", runtime, test.expectedRuntime)
+			}
+		})
+	}
+}
+
+// Test method used to test the formats of the runtimes.
+func TestRuntimeFormat(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		format        string
+		expectedValue any
+	}{
+		{RuntimeFormatMins, "135 mins"},
+		{RuntimeFormatMinutes, int32(135)},
+		{RuntimeFormatISO8601, "PT135M"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			if value := Runtime(135).Format(test.format); value != test.expectedValue {
+				t.Errorf("got %v, expected %v", value, test.expectedValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxSimilarMovies is the maximum number of movies ranked by Similar.
+const maxSimilarMovies = 100
+
+// MovieScore is a movie ranked by its similarity to another movie. Score ranges from 0
+// (nothing in common) to 1.
+type MovieScore struct {
+	MovieID int64
+	Score   float64
+}
+
+// Similar is a method that ranks the movies not in the trash sharing at least one genre
+// with the given movie, the most similar first. The score of a candidate is a weighted sum
+// of:
+//   - the overlap of the genres (shared genres over all the genres of both movies);
+//   - the proximity of the release years, halved at 5 years apart;
+//   - the similarity of the runtimes, halved at 15 minutes apart;
+//   - its ratings, weighted by their number, only if it has been rated: the weights of
+//     the other components are raised otherwise, so unrated movies are not penalized.
+func (m *MovieModel) Similar(movie *Movie) ([]MovieScore, error) {
+	query := `WITH candidates AS (
+				  SELECT id,
+				  	(SELECT count(DISTINCT g) FROM unnest(genres) g WHERE g = ANY($2))::float8
+				  		/ GREATEST((SELECT count(DISTINCT g) FROM unnest(genres || $2::text[]) g), 1) AS genres_score,
+				  	1 / (1 + abs(year - $3) / 5.0) AS year_score,
+				  	1 / (1 + abs(runtime - $4) / 15.0) AS runtime_score,
+				  	CASE WHEN ratings_count > 0
+				  		THEN average_rating / 10 * ratings_count / (ratings_count + 10.0)
+				  	END AS ratings_score
+				  FROM movies
+				  WHERE deleted_at IS NULL AND id <> $1 AND genres && $2
+			  )
+			  SELECT id, round(CASE WHEN ratings_score IS NULL
+			  	THEN 0.5 * genres_score + 0.25 * year_score + 0.25 * runtime_score
+			  	ELSE 0.45 * genres_score + 0.2 * year_score + 0.2 * runtime_score + 0.15 * ratings_score
+			  END, 4)::float8 AS score
+			  FROM candidates
+			  ORDER BY score DESC, id ASC
+			  LIMIT $5`
+
+	args := []any{movie.ID, pq.Array(movie.Genres), movie.Year, movie.Runtime, maxSimilarMovies}
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := []MovieScore{}
+	for rows.Next() {
+		var score MovieScore
+
+		err := rows.Scan(&score.MovieID, &score.Score)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+// PageScores returns the page of the ranked movies selected by the filters, along with
+// its pagination metadata. Only Page and PageSize are used.
+func PageScores(scores []MovieScore, filters Filters) ([]MovieScore, Metadata) {
+	start := min(filters.offset(), len(scores))
+	end := min(start+filters.limit(), len(scores))
+
+	return scores[start:end], calculateMetadata(len(scores), filters.Page, filters.PageSize)
+}
@@ -0,0 +1,46 @@
+package data
+
+import "testing"
+
+// Test method used to test the pagination of the ranked movies.
+func TestPageScores(t *testing.T) {
+	scores := []MovieScore{{1, 0.9}, {2, 0.8}, {3, 0.7}, {4, 0.6}, {5, 0.5}}
+
+	// Tests to be run.
+	tests := []struct {
+		name             string
+		filters          Filters
+		expectedIDs      []int64
+		expectedLastPage int
+	}{
+		{"first page", Filters{Page: 1, PageSize: 2}, []int64{1, 2}, 3},
+		{"last page", Filters{Page: 3, PageSize: 2}, []int64{5}, 3},
+		{"page after the last one", Filters{Page: 4, PageSize: 2}, []int64{}, 3},
+		{"single page", Filters{Page: 1, PageSize: 20}, []int64{1, 2, 3, 4, 5}, 1},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, metadata := PageScores(scores, test.filters)
+
+			if len(page) != len(test.expectedIDs) {
+				t.Fatalf("got %d movies, expected %d", len(page), len(test.expectedIDs))
+			}
+
+			for i, score := range page {
+				if score.MovieID != test.expectedIDs[i] {
+					t.Errorf("got %d, expected %d", score.MovieID, test.expectedIDs[i])
+				}
+			}
+
+			if metadata.LastPage != test.expectedLastPage {
+				t.Errorf("got last page %d, expected %d", metadata.LastPage, test.expectedLastPage)
+			}
+
+			if metadata.TotalRecords != len(scores) {
+				t.Errorf("got %d records, expected %d", metadata.TotalRecords, len(scores))
+			}
+		})
+	}
+}
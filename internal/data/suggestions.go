@@ -0,0 +1,76 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+)
+
+// MovieSuggestion is a struct that represents a movie suggested while typing its title.
+type MovieSuggestion struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Year  int32  `json:"year,omitempty"`
+}
+
+// NormalizePrefix returns the prefix used to suggest the movies: titles are matched
+// ignoring case and repeated spaces.
+func NormalizePrefix(prefix string) string {
+	return strings.Join(strings.Fields(strings.ToLower(prefix)), " ")
+}
+
+// ValidateSuggestion validates the normalized prefix and the number of movies to suggest.
+func ValidateSuggestion(v *validator.Validator, prefix string, limit int) {
+	v.Check(prefix != "", "q", "must be provided")
+	v.Check(len(prefix) <= 100, "q", "must not be more than 100 bytes long")
+
+	v.Check(limit > 0, "limit", "must be greater than zero")
+	v.Check(limit <= 20, "limit", "must be a maximum of 20")
+}
+
+// likePrefix returns the LIKE pattern matching the strings starting with prefix, escaping
+// its wildcards.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// Suggest is a method that retrieves the movies not in the trash whose title starts with
+// the normalized prefix, sorted by title. The movies are sorted by the expression and the
+// operator class of the movies_title_prefix_idx index, so that the index both matches
+// and sorts them, and the scan stops after limit movies.
+func (m *MovieModel) Suggest(prefix string, limit int) ([]*MovieSuggestion, error) {
+	query := `SELECT id, title, year
+			  FROM movies
+			  WHERE deleted_at IS NULL AND lower(title) LIKE $1
+			  ORDER BY lower(title) USING ~<~, id ASC
+			  LIMIT $2`
+
+	// Suggestions are requested on every keystroke, so they must be fast.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, likePrefix(prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []*MovieSuggestion{}
+	for rows.Next() {
+		var suggestion MovieSuggestion
+
+		err := rows.Scan(&suggestion.ID, &suggestion.Title, &suggestion.Year)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,27 @@
+package data
+
+import "testing"
+
+// Test method used to test the LIKE pattern used to suggest the movies by prefix.
+func TestLikePrefix(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		prefix         string
+		expectedResult string
+	}{
+		{"plain", NormalizePrefix("  The   LORD "), "the lord%"},
+		{"wildcards", "100% _x", `100\% \_x%`},
+		{"backslash", `a\b`, `a\\b%`},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := likePrefix(test.prefix)
+			if result != test.expectedResult {
+				t.Errorf("got %q, expected %q", result, test.expectedResult)
+			}
+		})
+	}
+}
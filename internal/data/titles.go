@@ -0,0 +1,203 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+	"github.com/lib/pq"
+)
+
+// LocaleRX matches the locales of the localized titles: a lowercase language code,
+// optionally followed by an uppercase region code (e.g. "it", "de-CH").
+var LocaleRX = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// MovieTitle is a struct that represents the title of a movie in a given locale.
+type MovieTitle struct {
+	MovieID int64  `json:"-"`
+	Locale  string `json:"locale"`
+	Title   string `json:"title"`
+}
+
+// NormalizeLocale returns the canonical form of a locale, e.g. "de-CH" for "DE_ch".
+func NormalizeLocale(locale string) string {
+	language, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !found {
+		return strings.ToLower(language)
+	}
+
+	return strings.ToLower(language) + "-" + strings.ToUpper(region)
+}
+
+// ValidateMovieTitle validates a localized title, whose locale should be normalized with
+// NormalizeLocale first.
+func ValidateMovieTitle(v *validator.Validator, title *MovieTitle) {
+	v.Check(validator.Matches(title.Locale, LocaleRX), "locale", "must be a valid locale (e.g. it or de-CH)")
+
+	v.Check(title.Title != "", "title", "must be provided")
+	v.Check(len(title.Title) <= 500, "title", "must not be more than 500 bytes long")
+}
+
+// LocalizedTitle returns the title matching the first possible locale of the preferences,
+// sorted from the most preferred one. A locale with a region also matches the title of its
+// language (e.g. "de-CH" matches "de"). It returns nil if no title matches.
+func LocalizedTitle(titles []*MovieTitle, preferences []string) *MovieTitle {
+	for _, preference := range preferences {
+		preference = NormalizeLocale(preference)
+		language, _, _ := strings.Cut(preference, "-")
+
+		var fallback *MovieTitle
+		for _, title := range titles {
+			switch title.Locale {
+			case preference:
+				return title
+			case language:
+				fallback = title
+			}
+		}
+
+		if fallback != nil {
+			return fallback
+		}
+	}
+
+	return nil
+}
+
+// Interface for the movie title model.
+type MovieTitleModelInterface interface {
+	GetAllForMovie(movieID int64) ([]*MovieTitle, error)
+	GetAllForMovies(movieIDs []int64) (map[int64][]*MovieTitle, error)
+	Upsert(movie *Movie, title *MovieTitle, userID int64) error
+	Delete(movie *Movie, locale string, userID int64) error
+}
+
+// Movie title model struct that wraps a db connection pool.
+type MovieTitleModel struct {
+	DB *sql.DB
+}
+
+// GetAllForMovie is a method that retrieves the localized titles of a movie, sorted by locale.
+func (m *MovieTitleModel) GetAllForMovie(movieID int64) ([]*MovieTitle, error) {
+	titles, err := m.GetAllForMovies([]int64{movieID})
+	if err != nil {
+		return nil, err
+	}
+
+	if titles[movieID] == nil {
+		return []*MovieTitle{}, nil
+	}
+
+	return titles[movieID], nil
+}
+
+// GetAllForMovies is a method that retrieves in a single query the localized titles of many
+// movies, mapped by movie ID. Movies without localized titles are not in the map.
+func (m *MovieTitleModel) GetAllForMovies(movieIDs []int64) (map[int64][]*MovieTitle, error) {
+	query := `SELECT movie_id, locale, title
+			  FROM movie_titles
+			  WHERE movie_id = ANY($1)
+			  ORDER BY locale ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(movieIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := map[int64][]*MovieTitle{}
+	for rows.Next() {
+		var title MovieTitle
+
+		err := rows.Scan(&title.MovieID, &title.Locale, &title.Title)
+		if err != nil {
+			return nil, err
+		}
+		titles[title.MovieID] = append(titles[title.MovieID], &title)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return titles, nil
+}
+
+// Upsert is a method for adding or replacing the title of a movie in a locale. The version
+// of the movie is incremented, so that the ETag of the movie changes with its titles.
+func (m *MovieTitleModel) Upsert(movie *Movie, title *MovieTitle, userID int64) error {
+	query := `INSERT INTO movie_titles (movie_id, locale, title)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (movie_id, locale) DO UPDATE SET title = EXCLUDED.title`
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = bumpMovieVersion(ctx, tx, movie, userID)
+	if err != nil {
+		return err
+	}
+
+	title.MovieID = movie.ID
+
+	_, err = tx.ExecContext(ctx, query, title.MovieID, title.Locale, title.Title)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete is a method for removing the title of a movie in a locale. As for Upsert, the
+// version of the movie is incremented.
+func (m *MovieTitleModel) Delete(movie *Movie, locale string, userID int64) error {
+	query := `DELETE FROM movie_titles
+			  WHERE movie_id = $1 AND locale = $2`
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Begin the transaction. Rollback is a no-op if the transaction has been committed.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, movie.ID, locale)
+	if err != nil {
+		return err
+	}
+
+	// If no rows were affected, the movie has no title in the locale.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	err = bumpMovieVersion(ctx, tx, movie, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
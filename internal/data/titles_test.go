@@ -0,0 +1,63 @@
+package data
+
+import "testing"
+
+// Test method used to test the normalization of the locales.
+func TestNormalizeLocale(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		locale         string
+		expectedLocale string
+	}{
+		{"it", "it"},
+		{"IT", "it"},
+		{"de-ch", "de-CH"},
+		{" DE_ch ", "de-CH"},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.locale, func(t *testing.T) {
+			if locale := NormalizeLocale(test.locale); locale != test.expectedLocale {
+				t.Errorf("got %q, expected %q", locale, test.expectedLocale)
+			}
+		})
+	}
+}
+
+// Test method used to test the choice of the localized title.
+func TestLocalizedTitle(t *testing.T) {
+	titles := []*MovieTitle{
+		{Locale: "de", Title: "Der Herr der Ringe"},
+		{Locale: "de-CH", Title: "Der Herr der Ringe (CH)"},
+		{Locale: "it", Title: "Il Signore degli Anelli"},
+	}
+
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		preferences   []string
+		expectedTitle string
+	}{
+		{"exact locale", []string{"de-CH"}, "Der Herr der Ringe (CH)"},
+		{"language of the locale", []string{"it-IT"}, "Il Signore degli Anelli"},
+		{"next preference", []string{"fr", "it"}, "Il Signore degli Anelli"},
+		{"case insensitive", []string{"DE"}, "Der Herr der Ringe"},
+		{"no match", []string{"en", "fr"}, ""},
+		{"no preference", nil, ""},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			title := LocalizedTitle(titles, test.preferences)
+
+			switch {
+			case test.expectedTitle == "" && title != nil:
+				t.Errorf("got %q, expected no title", title.Title)
+			case test.expectedTitle != "" && (title == nil || title.Title != test.expectedTitle):
+				t.Errorf("got %v, expected %q", title, test.expectedTitle)
+			}
+		})
+	}
+}
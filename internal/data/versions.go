@@ -0,0 +1,225 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MovieVersion is a struct that represents a snapshot of a movie, taken each time the
+// movie is created or updated.
+type MovieVersion struct {
+	MovieID       int64     `json:"movie_id"`
+	Version       int32     `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UserID        *int64    `json:"user_id"` // nil if the author of the version is unknown
+	Title         string    `json:"title"`
+	Year          int32     `json:"year"`
+	Runtime       Runtime   `json:"runtime"`
+	Genres        []string  `json:"genres"`
+	RuntimeFormat string    `json:"-"` // Format of the runtimes in JSON, one of RuntimeFormats (legacy if empty)
+}
+
+// MarshalJSON encodes the version to JSON, with its runtime in RuntimeFormat.
+func (v MovieVersion) MarshalJSON() ([]byte, error) {
+	// The versionJSON type has the fields of MovieVersion but not its methods, so that
+	// it is encoded by the default encoder. The runtime field shadows the one of the version.
+	type versionJSON MovieVersion
+
+	return json.Marshal(struct {
+		versionJSON
+		Runtime any `json:"runtime"`
+	}{versionJSON(v), v.Runtime.Format(v.RuntimeFormat)})
+}
+
+// formatRuntime returns a runtime to be reported as a change of the version, in
+// RuntimeFormat if set.
+func (v *MovieVersion) formatRuntime(runtime Runtime) any {
+	if v.RuntimeFormat == "" {
+		return runtime
+	}
+
+	return runtime.Format(v.RuntimeFormat)
+}
+
+// Change is a struct that holds the previous and the current value of a field.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Diff returns the fields that changed between the previous version and this one. If
+// previous is nil (i.e. this is the first version) every field is reported as changed.
+func (v *MovieVersion) Diff(previous *MovieVersion) map[string]Change {
+	changes := map[string]Change{}
+
+	if previous == nil {
+		changes["title"] = Change{To: v.Title}
+		changes["year"] = Change{To: v.Year}
+		changes["runtime"] = Change{To: v.formatRuntime(v.Runtime)}
+		changes["genres"] = Change{To: v.Genres}
+		return changes
+	}
+
+	if previous.Title != v.Title {
+		changes["title"] = Change{From: previous.Title, To: v.Title}
+	}
+	if previous.Year != v.Year {
+		changes["year"] = Change{From: previous.Year, To: v.Year}
+	}
+	if previous.Runtime != v.Runtime {
+		changes["runtime"] = Change{From: v.formatRuntime(previous.Runtime), To: v.formatRuntime(v.Runtime)}
+	}
+	if !slices.Equal(previous.Genres, v.Genres) {
+		changes["genres"] = Change{From: previous.Genres, To: v.Genres}
+	}
+
+	return changes
+}
+
+// Interface for the movie version model.
+type MovieVersionModelInterface interface {
+	GetAllForMovie(movieID int64, filters Filters) ([]*MovieVersion, Metadata, error)
+	Get(movieID int64, version int32) (*MovieVersion, error)
+}
+
+// Movie version model struct that wraps a db connection pool.
+type MovieVersionModel struct {
+	DB *sql.DB
+}
+
+// insertMovieVersion records the current state of a movie as a new version, within the
+// same transaction that created or updated it.
+func insertMovieVersion(ctx context.Context, tx *sql.Tx, movie *Movie, userID int64) error {
+	query := `INSERT INTO movie_versions (movie_id, version, user_id, title, year, runtime, genres)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	args := []any{movie.ID, movie.Version, userID, movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres)}
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// bumpMovieVersion increments the version of a movie whose related records changed, within
+// the transaction changing them, and records the new version. As for MovieModel.Update, an
+// ErrEditConflict error is returned if the version of the movie changed in the meantime.
+func bumpMovieVersion(ctx context.Context, tx *sql.Tx, movie *Movie, userID int64) error {
+	query := `UPDATE movies
+			  SET version = version + 1
+			  WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+			  RETURNING version`
+
+	err := tx.QueryRowContext(ctx, query, movie.ID, movie.Version).Scan(&movie.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return insertMovieVersion(ctx, tx, movie, userID)
+}
+
+// GetAllForMovie is a method that retrieves all the versions of a movie from the DB,
+// sorted by version number.
+func (m *MovieVersionModel) GetAllForMovie(movieID int64, filters Filters) ([]*MovieVersion, Metadata, error) {
+	// SQL query to retrieve the versions of a movie. Versions of movies in the trash
+	// are not visible.
+	query := `SELECT count(*) OVER(), movie_versions.movie_id, movie_versions.version, movie_versions.created_at,
+					 movie_versions.user_id, movie_versions.title, movie_versions.year, movie_versions.runtime, movie_versions.genres
+			  FROM movie_versions
+			  INNER JOIN movies ON movies.id = movie_versions.movie_id
+			  WHERE movie_versions.movie_id = $1 AND movies.deleted_at IS NULL
+			  ORDER BY movie_versions.version ` + filters.sortDirection() + `
+			  LIMIT $2 OFFSET $3`
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Executes the query.
+	rows, err := m.DB.QueryContext(ctx, query, movieID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	// Scan rows to fill the result and metadata struct.
+	totalRecords := 0
+	versions := []*MovieVersion{}
+	for rows.Next() {
+		var version MovieVersion
+
+		err := rows.Scan(
+			&totalRecords,
+			&version.MovieID,
+			&version.Version,
+			&version.CreatedAt,
+			&version.UserID,
+			&version.Title,
+			&version.Year,
+			&version.Runtime,
+			pq.Array(&version.Genres))
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		versions = append(versions, &version)
+	}
+
+	// Checks again for errors.
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return versions, metadata, nil
+}
+
+// Get is a method for fetching a specific version of a movie.
+func (m *MovieVersionModel) Get(movieID int64, version int32) (*MovieVersion, error) {
+	// Sanitize IDs.
+	if movieID < 1 || version < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT movie_versions.movie_id, movie_versions.version, movie_versions.created_at,
+					 movie_versions.user_id, movie_versions.title, movie_versions.year, movie_versions.runtime, movie_versions.genres
+			  FROM movie_versions
+			  INNER JOIN movies ON movies.id = movie_versions.movie_id
+			  WHERE movie_versions.movie_id = $1 AND movie_versions.version = $2 AND movies.deleted_at IS NULL`
+
+	var movieVersion MovieVersion
+
+	// Creates a context with a 3 seconds timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, movieID, version).Scan(
+		&movieVersion.MovieID,
+		&movieVersion.Version,
+		&movieVersion.CreatedAt,
+		&movieVersion.UserID,
+		&movieVersion.Title,
+		&movieVersion.Year,
+		&movieVersion.Runtime,
+		pq.Array(&movieVersion.Genres),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &movieVersion, nil
+}
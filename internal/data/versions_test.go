@@ -0,0 +1,45 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test method used to test the Diff method of MovieVersion.
+func TestMovieVersionDiff(t *testing.T) {
+	first := &MovieVersion{Version: 1, Title: "Alien", Year: 1979, Runtime: 117, Genres: []string{"horror"}}
+	second := &MovieVersion{Version: 2, Title: "Alien", Year: 1979, Runtime: 116, Genres: []string{"horror", "sci-fi"}}
+
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		version        *MovieVersion
+		previous       *MovieVersion
+		expectedResult map[string]Change
+	}{
+		{"first version", first, nil, map[string]Change{
+			"title":   {To: "Alien"},
+			"year":    {To: int32(1979)},
+			"runtime": {To: Runtime(117)},
+			"genres":  {To: []string{"horror"}},
+		}},
+		{"changed fields", second, first, map[string]Change{
+			"runtime": {From: Runtime(117), To: Runtime(116)},
+			"genres":  {From: []string{"horror"}, To: []string{"horror", "sci-fi"}},
+		}},
+		{"no changes", first, first, map[string]Change{}},
+		{"formatted runtime", &MovieVersion{Version: 2, Title: "Alien", Year: 1979, Runtime: 116, Genres: []string{"horror"}, RuntimeFormat: RuntimeFormatMinutes}, first, map[string]Change{
+			"runtime": {From: int32(117), To: int32(116)},
+		}},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.version.Diff(test.previous)
+			if !reflect.DeepEqual(result, test.expectedResult) {
+				t.Errorf("got %+v, expected %+v", result, test.expectedResult)
+			}
+		})
+	}
+}
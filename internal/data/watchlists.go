@@ -0,0 +1,372 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlessioPani/go-greenlight/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Custom error for a movie that is already in the watchlist of a user.
+var ErrDuplicateWatchlistItem = errors.New("duplicate watchlist item")
+
+// WatchlistItem is a struct that represents a movie in the watchlist of a user.
+type WatchlistItem struct {
+	UserID    int64     `json:"-"`
+	MovieID   int64     `json:"movie_id"`
+	Movie     *Movie    `json:"movie,omitempty"`
+	Position  int32     `json:"position"`
+	AddedAt   time.Time `json:"added_at"`
+	WatchedOn *Date     `json:"watched_on"` // nil if the movie hasn't been watched yet
+}
+
+// sortValue returns the value of the item for the column used to sort a watchlist, as
+// stored in a pagination cursor.
+func (item *WatchlistItem) sortValue(column string) string {
+	switch column {
+	case "position":
+		return strconv.FormatInt(int64(item.Position), 10)
+	case "added_at":
+		return item.AddedAt.Format(time.RFC3339)
+	default:
+		return item.Movie.sortValue(column)
+	}
+}
+
+// ValidateWatchlistItem validates a watchlist item by checking its fields.
+func ValidateWatchlistItem(v *validator.Validator, item *WatchlistItem) {
+	v.Check(item.Position >= 1, "position", "must be at least 1")
+
+	if item.WatchedOn != nil {
+		v.Check(!item.WatchedOn.After(Today().Time), "watched_on", "must not be in the future")
+	}
+}
+
+// Interface for the watchlist model.
+type WatchlistModelInterface interface {
+	Add(item *WatchlistItem) error
+	Get(userID int64, movieID int64) (*WatchlistItem, error)
+	GetAllForUser(userID int64, watched *bool, filters Filters) ([]*WatchlistItem, Metadata, error)
+	Update(item *WatchlistItem) error
+	Remove(userID int64, movieID int64) error
+}
+
+// Watchlist model struct that wraps a db connection pool.
+type WatchlistModel struct {
+	DB *sql.DB
+}
+
+// lockWatchlist locks the watchlist of a user until the end of the transaction, so that
+// concurrent changes don't mix up their positions. The user is locked rather than the
+// items, which would lock nothing while the watchlist is empty.
+func lockWatchlist(ctx context.Context, tx *sql.Tx, userID int64) error {
+	_, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE id = $1 FOR NO KEY UPDATE", userID)
+	return err
+}
+
+// Add is a method used to append a movie to the watchlist of a user.
+func (m *WatchlistModel) Add(item *WatchlistItem) error {
+	// Only movies that are not in the trash can be added.
+	query := `INSERT INTO watchlist_items (user_id, movie_id, position, watched_on)
+			  SELECT $1, id, (SELECT coalesce(max(position), 0) + 1 FROM watchlist_items WHERE user_id = $1), $3
+			  FROM movies
+			  WHERE id = $2 AND deleted_at IS NULL
+			  RETURNING position, added_at`
+
+	args := []any{item.UserID, item.MovieID, item.WatchedOn}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockWatchlist(ctx, tx, item.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&item.Position, &item.AddedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		case strings.Contains(err.Error(), "watchlist_items_pkey"):
+			return ErrDuplicateWatchlistItem
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get is a method used to retrieve a movie from the watchlist of a user.
+func (m *WatchlistModel) Get(userID int64, movieID int64) (*WatchlistItem, error) {
+	if movieID < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT movies.id, movies.created_at, title, year, runtime, genres, movies.version, average_rating, ratings_count,
+					 position, added_at, watched_on
+			  FROM watchlist_items
+			  INNER JOIN movies ON movies.id = watchlist_items.movie_id
+			  WHERE watchlist_items.user_id = $1 AND watchlist_items.movie_id = $2 AND movies.deleted_at IS NULL`
+
+	item := WatchlistItem{UserID: userID, Movie: &Movie{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userID, movieID).Scan(
+		&item.Movie.ID,
+		&item.Movie.CreatedAt,
+		&item.Movie.Title,
+		&item.Movie.Year,
+		&item.Movie.Runtime,
+		pq.Array(&item.Movie.Genres),
+		&item.Movie.Version,
+		&item.Movie.AverageRating,
+		&item.Movie.RatingsCount,
+		&item.Position,
+		&item.AddedAt,
+		&item.WatchedOn,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	item.MovieID = item.Movie.ID
+
+	return &item, nil
+}
+
+// GetAllForUser is a method that retrieves the watchlist of a user from the DB. If watched
+// is not nil, only the movies that have (or haven't) been watched are returned. Pagination
+// works as in MovieModel.GetAll, cursors included.
+func (m *WatchlistModel) GetAllForUser(userID int64, watched *bool, filters Filters) ([]*WatchlistItem, Metadata, error) {
+	// WHERE clause shared by the data and the count queries. Movies in the trash are
+	// not visible.
+	where := `WHERE watchlist_items.user_id = $1 AND movies.deleted_at IS NULL
+			  AND ($2::boolean IS NULL OR (watched_on IS NOT NULL) = $2)`
+
+	from := `FROM watchlist_items
+			 INNER JOIN movies ON movies.id = watchlist_items.movie_id `
+
+	// See MovieModel.GetAll for the reason of the separate count in cursor mode.
+	countColumn := "count(*) OVER()"
+	if filters.SkipTotal || filters.Cursor != "" {
+		countColumn = "0"
+	}
+
+	// Column used to sort the watchlist.
+	sortColumn := movieSortColumn(filters.sortColumn())
+
+	// Values for the placeholders in the query.
+	args := []any{userID, watched, filters.limit() + 1, filters.offset()}
+
+	// Add the keyset condition if a cursor has been provided.
+	keyset := ""
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		keyset = "AND " + filters.keysetClause(sortColumn, 5, 6)
+		args[3] = 0
+		args = append(args, c.Value, c.ID)
+	}
+
+	// The id column belongs to the movies table only, so it is the id of the movie.
+	query := fmt.Sprintf(`SELECT %s, movies.id, movies.created_at, title, year, runtime, genres, movies.version, average_rating, ratings_count,
+								 position, added_at, watched_on
+						  %s
+						  %s
+						  %s
+						  ORDER BY %s %s, id ASC
+						  LIMIT $3 OFFSET $4`, countColumn, from, where, keyset, sortColumn, filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	items := []*WatchlistItem{}
+	for rows.Next() {
+		item := WatchlistItem{UserID: userID, Movie: &Movie{}}
+
+		err := rows.Scan(
+			&totalRecords,
+			&item.Movie.ID,
+			&item.Movie.CreatedAt,
+			&item.Movie.Title,
+			&item.Movie.Year,
+			&item.Movie.Runtime,
+			pq.Array(&item.Movie.Genres),
+			&item.Movie.Version,
+			&item.Movie.AverageRating,
+			&item.Movie.RatingsCount,
+			&item.Position,
+			&item.AddedAt,
+			&item.WatchedOn)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		item.MovieID = item.Movie.ID
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	// Count the total records matching the filters, if a cursor has been used.
+	if filters.Cursor != "" && !filters.SkipTotal {
+		err = m.DB.QueryRowContext(ctx, "SELECT count(*) "+from+where, args[:2]...).Scan(&totalRecords)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+	}
+
+	metadata := filters.pageMetadata(totalRecords)
+
+	// If the extra record has been fetched, drop it and return the cursor of the next page.
+	if len(items) > filters.limit() {
+		items = items[:filters.limit()]
+		last := items[len(items)-1]
+		metadata.NextCursor = filters.nextCursor(last.sortValue(sortColumn), last.MovieID)
+	}
+
+	return items, metadata, nil
+}
+
+// Update is a method used to move a movie to another position of the watchlist and to
+// set the date it was watched on. The other movies are shifted to make room for it, and
+// a position past the end of the watchlist moves the movie to the last place.
+func (m *WatchlistModel) Update(item *WatchlistItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockWatchlist(ctx, tx, item.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Get the current position of the movie and the length of the watchlist.
+	query := `SELECT position, (SELECT count(*) FROM watchlist_items WHERE user_id = $1)
+			  FROM watchlist_items
+			  WHERE user_id = $1 AND movie_id = $2`
+
+	var current, length int32
+
+	err = tx.QueryRowContext(ctx, query, item.UserID, item.MovieID).Scan(&current, &length)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	item.Position = min(item.Position, length)
+
+	if item.Position != current {
+		// Close the gap left by the movie, then open a new one at its new position.
+		query = `UPDATE watchlist_items SET position = position - 1 WHERE user_id = $1 AND position > $2`
+		_, err = tx.ExecContext(ctx, query, item.UserID, current)
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE watchlist_items SET position = position + 1 WHERE user_id = $1 AND position >= $2 AND movie_id <> $3`
+		_, err = tx.ExecContext(ctx, query, item.UserID, item.Position, item.MovieID)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE watchlist_items
+			 SET position = $1, watched_on = $2
+			 WHERE user_id = $3 AND movie_id = $4`
+
+	_, err = tx.ExecContext(ctx, query, item.Position, item.WatchedOn, item.UserID, item.MovieID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Remove is a method used to remove a movie from the watchlist of a user, shifting the
+// following movies up by one position.
+func (m *WatchlistModel) Remove(userID int64, movieID int64) error {
+	if movieID < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM watchlist_items
+			  WHERE user_id = $1 AND movie_id = $2
+			  RETURNING position`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockWatchlist(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	var position int32
+
+	err = tx.QueryRowContext(ctx, query, userID, movieID).Scan(&position)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	query = `UPDATE watchlist_items SET position = position - 1 WHERE user_id = $1 AND position > $2`
+
+	_, err = tx.ExecContext(ctx, query, userID, position)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
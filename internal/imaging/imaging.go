@@ -0,0 +1,121 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrUnsupportedFormat is returned if the image is neither a JPEG nor a PNG.
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+
+	// ErrTooLarge is returned if the image has more pixels than allowed.
+	ErrTooLarge = errors.New("image too large")
+)
+
+// ContentTypes are the media types of the images that can be decoded.
+var ContentTypes = []string{"image/jpeg", "image/png"}
+
+// jpegQuality is the quality of the encoded JPEG images.
+const jpegQuality = 85
+
+// Decode decodes a JPEG or PNG image, whose format is detected from its content rather
+// than trusting the client. The dimensions are checked before decoding, so that a small
+// file can't expand to more than maxPixels pixels in memory.
+//
+// The image is flattened over a white background, and re-encoding it drops all of its
+// metadata, EXIF included.
+func Decode(content []byte, maxPixels int) (*image.RGBA, error) {
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+
+	switch http.DetectContentType(content) {
+	case "image/jpeg":
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+	case "image/png":
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, err := decodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width > maxPixels/config.Height {
+		return nil, ErrTooLarge
+	}
+
+	src, err := decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Over)
+
+	return dst, nil
+}
+
+// Resize scales the image down to the given width, keeping its aspect ratio. Each pixel
+// of the thumbnail is the average of the pixels it covers in the source image (box
+// filter). Images narrower than the width are returned as they are.
+func Resize(src *image.RGBA, width int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	if width <= 0 || width >= srcW {
+		return src
+	}
+
+	height := max(1, (srcH*width+srcW/2)/srcW)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(src.Bounds().Min.X+x0, src.Bounds().Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8((r + n/2) / n)
+			dst.Pix[i+1] = uint8((g + n/2) / n)
+			dst.Pix[i+2] = uint8((b + n/2) / n)
+			dst.Pix[i+3] = uint8((a + n/2) / n)
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG encodes the image as a JPEG.
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,159 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPNG returns a PNG image of the given size, filled with the color.
+func testPNG(t *testing.T, width, height int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 (EXIF) segment right after the start of a JPEG image.
+func withEXIF(content []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), []byte("GPS 45.4642N 9.1900E")...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	return append(append(append([]byte{}, content[:2]...), segment...), content[2:]...)
+}
+
+// Test method used to test the decoding of the uploaded images.
+func TestDecode(t *testing.T) {
+	red := testPNG(t, 40, 20, color.RGBA{255, 0, 0, 255})
+
+	img, err := Decode(red, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redJPEG, err := EncodeJPEG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		content       []byte
+		maxPixels     int
+		expectedSize  image.Point
+		expectedPixel color.RGBA
+		expectedError error
+	}{
+		{"png", red, 1000, image.Pt(40, 20), color.RGBA{255, 0, 0, 255}, nil},
+		{"transparent png", testPNG(t, 2, 2, color.Transparent), 1000, image.Pt(2, 2), color.RGBA{255, 255, 255, 255}, nil},
+		{"jpeg with exif", withEXIF(redJPEG), 1000, image.Pt(40, 20), color.RGBA{}, nil},
+		{"too many pixels", red, 799, image.Point{}, color.RGBA{}, ErrTooLarge},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00"), 1000, image.Point{}, color.RGBA{}, ErrUnsupportedFormat},
+		{"text", []byte("not an image"), 1000, image.Point{}, color.RGBA{}, ErrUnsupportedFormat},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img, err := Decode(test.content, test.maxPixels)
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedError)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if img.Bounds().Size() != test.expectedSize {
+				t.Errorf("got size %v, expected %v", img.Bounds().Size(), test.expectedSize)
+			}
+
+			// JPEG is lossy, so the colors of the decoded JPEG are not checked.
+			if pixel := img.RGBAAt(0, 0); test.expectedPixel != (color.RGBA{}) && pixel != test.expectedPixel {
+				t.Errorf("got pixel %v, expected %v", pixel, test.expectedPixel)
+			}
+		})
+	}
+}
+
+// Test method used to test that re-encoding an image drops its EXIF metadata.
+func TestEncodeJPEGStripsEXIF(t *testing.T) {
+	img, err := Decode(testPNG(t, 8, 8, color.White), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := EncodeJPEG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err = Decode(withEXIF(content), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err = EncodeJPEG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(content, []byte("Exif")) {
+		t.Error("got EXIF metadata in the encoded image")
+	}
+}
+
+// Test method used to test the thumbnails generation.
+func TestResize(t *testing.T) {
+	// Left half black, right half white.
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				src.Set(x, y, color.Black)
+			} else {
+				src.Set(x, y, color.White)
+			}
+		}
+	}
+
+	// Tests to be run.
+	tests := []struct {
+		name          string
+		width         int
+		expectedSize  image.Point
+		expectedPixel color.RGBA
+	}{
+		{"half width", 2, image.Pt(2, 1), color.RGBA{0, 0, 0, 255}},
+		{"single pixel", 1, image.Pt(1, 1), color.RGBA{128, 128, 128, 255}},
+		{"wider than the source", 10, image.Pt(4, 2), color.RGBA{0, 0, 0, 255}},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dst := Resize(src, test.width)
+
+			if dst.Bounds().Size() != test.expectedSize {
+				t.Errorf("got size %v, expected %v", dst.Bounds().Size(), test.expectedSize)
+			}
+
+			if pixel := dst.RGBAAt(0, 0); pixel != test.expectedPixel {
+				t.Errorf("got pixel %v, expected %v", pixel, test.expectedPixel)
+			}
+		})
+	}
+}
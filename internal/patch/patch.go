@@ -0,0 +1,345 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidOperation is returned if an operation of a JSON Patch is malformed.
+	ErrInvalidOperation = errors.New("invalid operation")
+	// ErrPathNotFound is returned if the path of an operation doesn't point to an
+	// existing location of the document.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrTestFailed is returned if a test operation of a JSON Patch fails.
+	ErrTestFailed = errors.New("test failed")
+)
+
+// Operation is an operation of a JSON Patch document (RFC 6902).
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"` // nil if the member is missing, "null" if null
+}
+
+// Merge applies a JSON Merge Patch (RFC 7396) to a decoded JSON document and returns the
+// patched document: objects are merged recursively, null values remove the members and
+// any other value replaces the target. The target is modified in place.
+func Merge(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = Merge(t[key], value)
+		}
+	}
+
+	return t
+}
+
+// Apply applies the operations of a JSON Patch (RFC 6902) in order to a decoded JSON
+// document and returns the patched document. It stops at the first failing operation,
+// returning an error wrapping ErrInvalidOperation, ErrPathNotFound or ErrTestFailed.
+// The document is modified in place, so it must be discarded if an error is returned.
+func Apply(doc any, operations []Operation) (any, error) {
+	for i, operation := range operations {
+		var err error
+
+		doc, err = apply(doc, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+// apply applies a single operation to the document.
+func apply(doc any, operation Operation) (any, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		value, err := operation.value()
+		if err != nil {
+			return nil, err
+		}
+
+		switch operation.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			doc, _, err = remove(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any
+		if operation.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: a location can't be moved into one of its children", ErrInvalidOperation)
+			}
+			doc, value, err = remove(doc, from)
+		} else {
+			value, err = get(doc, from)
+			if err == nil {
+				value, err = deepCopy(value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, operation.Op)
+	}
+}
+
+// value returns the decoded value of the operation.
+func (operation Operation) value() (any, error) {
+	if operation.Value == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrInvalidOperation)
+	}
+
+	var value any
+	err := json.Unmarshal(operation.Value, &value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid value", ErrInvalidOperation)
+	}
+
+	return value, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens. The
+// empty pointer, which points to the whole document, has no tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: %q is not a valid JSON pointer", ErrInvalidOperation, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+// isPrefix reports whether the tokens of prefix are the first tokens of path.
+func isPrefix(prefix []string, path []string) bool {
+	return len(prefix) <= len(path) && reflect.DeepEqual(prefix, path[:len(prefix)])
+}
+
+// arrayIndex parses the token pointing to an element of an array of size n. The index n
+// itself ("-" as well) is allowed only if end is true, to add an element at the end.
+func arrayIndex(token string, n int, end bool) (int, error) {
+	if token == "-" && end {
+		return n, nil
+	}
+
+	// Leading zeros are not allowed.
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: %q is not a valid array index", ErrInvalidOperation, token)
+	}
+
+	if i > n || (i == n && !end) {
+		return 0, ErrPathNotFound
+	}
+
+	return i, nil
+}
+
+// get returns the value at the path of the document.
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			node = child
+		case []any:
+			i, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return node, nil
+}
+
+// add adds a value at the path of the node, replacing the member of an object or
+// inserting the element of an array, and returns the new node.
+func add(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token, last := path[0], len(path) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		if last {
+			n[token] = value
+			return n, nil
+		}
+
+		child, ok := n[token]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+
+		child, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = child
+
+		return n, nil
+
+	case []any:
+		i, err := arrayIndex(token, len(n), last)
+		if err != nil {
+			return nil, err
+		}
+
+		if last {
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		}
+
+		child, err := add(n[i], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+
+		return n, nil
+
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+// remove removes the value at the path of the node, and returns the new node along with
+// the removed value. The whole document can't be removed.
+func remove(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: the whole document can't be removed", ErrInvalidOperation)
+	}
+
+	token, last := path[0], len(path) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, ErrPathNotFound
+		}
+
+		if last {
+			delete(n, token)
+			return n, child, nil
+		}
+
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = child
+
+		return n, removed, nil
+
+	case []any:
+		i, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if last {
+			removed := n[i]
+			return append(n[:i], n[i+1:]...), removed, nil
+		}
+
+		child, removed, err := remove(n[i], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[i] = child
+
+		return n, removed, nil
+
+	default:
+		return nil, nil, ErrPathNotFound
+	}
+}
+
+// deepCopy returns a copy of a decoded JSON value that doesn't share any object or array
+// with it.
+func deepCopy(value any) (any, error) {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied any
+	err = json.Unmarshal(js, &copied)
+
+	return copied, err
+}
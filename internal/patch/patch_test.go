@@ -0,0 +1,110 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// document is the JSON document the patches of the tests are applied to.
+const document = `{"title": "Alien", "year": 1979, "genres": ["horror", "sci-fi"], "a/b": {"c~d": 1}}`
+
+// decode is an helper that decodes a JSON value, failing the test if it is not valid.
+func decode(t *testing.T, js string) any {
+	t.Helper()
+
+	var value any
+	err := json.Unmarshal([]byte(js), &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+// Test method used to test the Apply function with the operations of RFC 6902.
+func TestApply(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		operations     string
+		expectedResult string
+		expectedError  error
+	}{
+		{"add member", `[{"op": "add", "path": "/runtime", "value": "117 mins"}]`, `{"title": "Alien", "year": 1979, "runtime": "117 mins", "genres": ["horror", "sci-fi"], "a/b": {"c~d": 1}}`, nil},
+		{"add element", `[{"op": "add", "path": "/genres/1", "value": "thriller"}]`, `{"title": "Alien", "year": 1979, "genres": ["horror", "thriller", "sci-fi"], "a/b": {"c~d": 1}}`, nil},
+		{"append element", `[{"op": "add", "path": "/genres/-", "value": "thriller"}]`, `{"title": "Alien", "year": 1979, "genres": ["horror", "sci-fi", "thriller"], "a/b": {"c~d": 1}}`, nil},
+		{"remove element", `[{"op": "remove", "path": "/genres/0"}]`, `{"title": "Alien", "year": 1979, "genres": ["sci-fi"], "a/b": {"c~d": 1}}`, nil},
+		{"replace escaped", `[{"op": "replace", "path": "/a~1b/c~0d", "value": 2}]`, `{"title": "Alien", "year": 1979, "genres": ["horror", "sci-fi"], "a/b": {"c~d": 2}}`, nil},
+		{"test and remove", `[{"op": "test", "path": "/genres/1", "value": "sci-fi"}, {"op": "remove", "path": "/genres/1"}]`, `{"title": "Alien", "year": 1979, "genres": ["horror"], "a/b": {"c~d": 1}}`, nil},
+		{"move", `[{"op": "move", "from": "/genres/0", "path": "/genres/-"}]`, `{"title": "Alien", "year": 1979, "genres": ["sci-fi", "horror"], "a/b": {"c~d": 1}}`, nil},
+		{"copy", `[{"op": "copy", "from": "/genres", "path": "/tags"}, {"op": "remove", "path": "/tags/0"}]`, `{"title": "Alien", "year": 1979, "genres": ["horror", "sci-fi"], "tags": ["sci-fi"], "a/b": {"c~d": 1}}`, nil},
+		{"replace document", `[{"op": "replace", "path": "", "value": {}}]`, `{}`, nil},
+		{"test failed", `[{"op": "test", "path": "/year", "value": 1980}]`, "", ErrTestFailed},
+		{"missing member", `[{"op": "replace", "path": "/runtime", "value": "117 mins"}]`, "", ErrPathNotFound},
+		{"index out of range", `[{"op": "add", "path": "/genres/3", "value": "thriller"}]`, "", ErrPathNotFound},
+		{"invalid index", `[{"op": "remove", "path": "/genres/01"}]`, "", ErrInvalidOperation},
+		{"missing value", `[{"op": "add", "path": "/runtime"}]`, "", ErrInvalidOperation},
+		{"unknown op", `[{"op": "delete", "path": "/year"}]`, "", ErrInvalidOperation},
+		{"invalid pointer", `[{"op": "remove", "path": "year"}]`, "", ErrInvalidOperation},
+		{"move into child", `[{"op": "move", "from": "/a~1b", "path": "/a~1b/e"}]`, "", ErrInvalidOperation},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var operations []Operation
+			err := json.Unmarshal([]byte(test.operations), &operations)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Apply(decode(t, document), operations)
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedError)
+			}
+
+			if test.expectedError == nil {
+				resultJS, _ := json.Marshal(result)
+				expectedJS, _ := json.Marshal(decode(t, test.expectedResult))
+
+				if string(resultJS) != string(expectedJS) {
+					t.Errorf("got %s, expected %s", resultJS, expectedJS)
+				}
+			}
+		})
+	}
+}
+
+// Test method used to test the Merge function with the examples of RFC 7396.
+func TestMerge(t *testing.T) {
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		target         string
+		patch          string
+		expectedResult string
+	}{
+		{"replace member", `{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{"add member", `{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{"remove member", `{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{"replace array", `{"a": ["b"]}`, `{"a": ["c", "d"]}`, `{"a": ["c", "d"]}`},
+		{"nested objects", `{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{"replace object", `{"a": "foo"}`, `["c"]`, `["c"]`},
+		{"object into scalar", `{"a": "foo"}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Merge(decode(t, test.target), decode(t, test.patch))
+
+			resultJS, _ := json.Marshal(result)
+			expectedJS, _ := json.Marshal(decode(t, test.expectedResult))
+
+			if string(resultJS) != string(expectedJS) {
+				t.Errorf("got %s, expected %s", resultJS, expectedJS)
+			}
+		})
+	}
+}
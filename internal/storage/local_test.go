@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Test method used to test storing, reading and deleting files with the local driver.
+func TestLocal(t *testing.T) {
+	l, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Put("movies/1/poster/original.jpg", strings.NewReader("poster"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tests to be run.
+	tests := []struct {
+		name           string
+		key            string
+		expectedResult string
+		expectedError  error
+	}{
+		{"existing file", "movies/1/poster/original.jpg", "poster", nil},
+		{"missing file", "movies/1/poster/w92.jpg", "", ErrNotFound},
+		{"directory", "movies/1/poster", "", ErrNotFound},
+		{"outside of the root", "../../movies/1/poster/original.jpg", "poster", nil},
+		{"empty key", "", "", ErrNotFound},
+	}
+
+	// Executes tests.
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := l.Get(test.key)
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("got error %v, expected %v", err, test.expectedError)
+			}
+
+			if err == nil {
+				defer file.Close()
+
+				content, err := io.ReadAll(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(content) != test.expectedResult {
+					t.Errorf("got %q, expected %q", content, test.expectedResult)
+				}
+			}
+		})
+	}
+
+	// Deleting a prefix removes all the files under it.
+	err = l.Delete("movies/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = l.Get("movies/1/poster/original.jpg")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, expected %v", err, ErrNotFound)
+	}
+}
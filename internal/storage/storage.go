@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned if no file is stored with the requested key.
+var ErrNotFound = errors.New("file not found")
+
+// Storage is the interface of the drivers storing the uploaded files. Files are identified
+// by keys made of segments separated by slashes, e.g. "movies/1/poster/original.jpg".
+type Storage interface {
+	// Put stores the content read from r with the key, replacing the existing file.
+	Put(key string, r io.Reader) error
+	// Get opens the file stored with the key. The caller must close it.
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the files whose keys start with the prefix followed by a slash,
+	// or the file stored with the prefix itself. Missing files are ignored.
+	Delete(prefix string) error
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    position integer NOT NULL CHECK (position > 0),
+    added_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    watched_on date,
+    PRIMARY KEY (user_id, movie_id),
+    -- The constraint is checked at commit, as the positions are shifted one row at a time.
+    CONSTRAINT watchlist_items_user_id_position_key UNIQUE (user_id, position) DEFERRABLE INITIALLY DEFERRED
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS credits;
+DROP TABLE IF EXISTS people;
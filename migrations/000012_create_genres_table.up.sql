@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS genres (
+    slug text PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    aliases text[] NOT NULL DEFAULT '{}'
+);
+
+-- Initial catalog. Aliases are matched ignoring case, spaces, dashes and underscores.
+INSERT INTO genres (slug, name, aliases) VALUES
+    ('action', 'Action', '{}'),
+    ('adventure', 'Adventure', '{}'),
+    ('animation', 'Animation', '{animated,cartoon}'),
+    ('biography', 'Biography', '{biopic,biographical}'),
+    ('comedy', 'Comedy', '{comedies}'),
+    ('crime', 'Crime', '{}'),
+    ('documentary', 'Documentary', '{doc,docs}'),
+    ('drama', 'Drama', '{dramas}'),
+    ('family', 'Family', '{kids,children}'),
+    ('fantasy', 'Fantasy', '{}'),
+    ('film-noir', 'Film Noir', '{noir}'),
+    ('history', 'History', '{historical}'),
+    ('horror', 'Horror', '{}'),
+    ('musical', 'Musical', '{music}'),
+    ('mystery', 'Mystery', '{}'),
+    ('romance', 'Romance', '{romantic}'),
+    ('science-fiction', 'Science Fiction', '{sci-fi,scifi,sf}'),
+    ('sport', 'Sport', '{sports}'),
+    ('thriller', 'Thriller', '{}'),
+    ('war', 'War', '{}'),
+    ('western', 'Western', '{}')
+ON CONFLICT (slug) DO NOTHING;
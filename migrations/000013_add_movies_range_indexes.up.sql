@@ -0,0 +1,4 @@
+-- Used by the range filters of the movie list. Movies in the trash are never listed.
+CREATE INDEX IF NOT EXISTS movies_year_idx ON movies (year) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS movies_runtime_idx ON movies (runtime) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS movies_created_at_idx ON movies (created_at) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Used by the fuzzy title search of the movie list. The extension is also created by the
+-- init script of the database, as it needs the privileges of the superuser on older versions.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS movies_title_trgm_idx ON movies USING GIN (title gin_trgm_ops) WHERE deleted_at IS NULL;
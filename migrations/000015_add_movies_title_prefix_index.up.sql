@@ -0,0 +1,3 @@
+-- Used by the title suggestions, matched by prefix ignoring case. text_pattern_ops lets
+-- LIKE 'prefix%' use the index whatever the collation of the database.
+CREATE INDEX IF NOT EXISTS movies_title_prefix_idx ON movies (lower(title) text_pattern_ops) WHERE deleted_at IS NULL;
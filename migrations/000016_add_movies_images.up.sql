@@ -0,0 +1,3 @@
+-- Storage keys of the poster and backdrop images, empty if they have not been uploaded.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS poster text NOT NULL DEFAULT '';
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS backdrop text NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS wikidata_id;
+ALTER TABLE movies DROP COLUMN IF EXISTS tmdb_id;
+ALTER TABLE movies DROP COLUMN IF EXISTS imdb_id;
@@ -0,0 +1,10 @@
+-- IDs of the movies in external catalogs, empty (or zero) if unknown. Movies in the trash
+-- keep their IDs, so that they can be restored.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS imdb_id text NOT NULL DEFAULT '';
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS tmdb_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS wikidata_id text NOT NULL DEFAULT '';
+
+-- Each ID is unique per provider, which also lets the lookups use the indexes.
+CREATE UNIQUE INDEX IF NOT EXISTS movies_imdb_id_key ON movies (imdb_id) WHERE imdb_id <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS movies_tmdb_id_key ON movies (tmdb_id) WHERE tmdb_id <> 0;
+CREATE UNIQUE INDEX IF NOT EXISTS movies_wikidata_id_key ON movies (wikidata_id) WHERE wikidata_id <> '';
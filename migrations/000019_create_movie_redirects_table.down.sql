@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code = 'movies:admin';
+DROP INDEX IF EXISTS movies_normalized_title_idx;
+DROP TABLE IF EXISTS movie_redirects;
@@ -0,0 +1,17 @@
+-- Movies merged into another one, whose IDs keep redirecting to the movie they have been
+-- merged into.
+CREATE TABLE IF NOT EXISTS movie_redirects (
+    old_id bigint PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    merged_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    user_id bigint REFERENCES users ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS movie_redirects_movie_id_idx ON movie_redirects (movie_id);
+
+-- Used by the duplicate check of the new movies, which ignores case, spaces and punctuation.
+CREATE INDEX IF NOT EXISTS movies_normalized_title_idx ON movies (regexp_replace(lower(title), '[^[:alnum:]]+', '', 'g'), year) WHERE deleted_at IS NULL;
+
+-- Merging movies is restricted to the administrators.
+INSERT INTO permissions (code)
+VALUES ('movies:admin');
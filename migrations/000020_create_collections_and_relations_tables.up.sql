@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS collections (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS collections_name_idx ON collections USING GIN (to_tsvector('simple', name));
+
+-- Movies of the collections, ordered by position from 1.
+CREATE TABLE IF NOT EXISTS collection_movies (
+    collection_id bigint NOT NULL REFERENCES collections ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    position integer NOT NULL CHECK (position > 0),
+    PRIMARY KEY (collection_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS collection_movies_movie_id_idx ON collection_movies (movie_id);
+
+-- Typed relations between movies, e.g. movie_id is a sequel of related_id.
+CREATE TABLE IF NOT EXISTS movie_relations (
+    id bigserial PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    related_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    type text NOT NULL CHECK (type IN ('sequel_of', 'remake_of', 'spin_off_of')),
+    CHECK (movie_id <> related_id)
+);
+
+-- The same relation can't be stored in both directions.
+CREATE UNIQUE INDEX IF NOT EXISTS movie_relations_pair_type_idx ON movie_relations (least(movie_id, related_id), greatest(movie_id, related_id), type);
+
+CREATE INDEX IF NOT EXISTS movie_relations_related_id_idx ON movie_relations (related_id);
@@ -0,0 +1,13 @@
+-- Releases of the movies per country (ISO 3166-1 alpha-2 code), along with the age
+-- certification given in that country, empty if unknown.
+CREATE TABLE IF NOT EXISTS movie_releases (
+    id bigserial PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    country text NOT NULL,
+    date date NOT NULL,
+    type text NOT NULL CHECK (type IN ('premiere', 'theatrical', 'digital', 'physical', 'tv')),
+    certification text NOT NULL DEFAULT '',
+    UNIQUE (movie_id, country, type)
+);
+
+CREATE INDEX IF NOT EXISTS movie_releases_country_certification_idx ON movie_releases (country, certification);